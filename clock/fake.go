@@ -0,0 +1,211 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock whose time only moves when Advance or Set is called.
+// Timers, tickers and sleepers fire as the time they wait for is passed.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+	changed chan struct{}
+}
+
+// Returns a Fake clock starting at the time.
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start, changed: make(chan struct{})}
+}
+
+// Must be called with f.mu held.
+func (f *Fake) notify() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	w := &fakeWaiter{fake: f, c: make(chan time.Time, 1)}
+	w.Reset(d)
+	return w
+}
+
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	w := &fakeWaiter{fake: f, c: make(chan time.Time, 1), period: d}
+	w.Reset(d)
+	return &fakeTicker{w}
+}
+
+// Blocks until the clock passes the duration.
+func (f *Fake) Sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	<-f.After(d)
+}
+
+// Moves the clock forward, firing every timer and ticker due by the new
+// time in the order they are due.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	f.setLocked(f.now.Add(d))
+	f.mu.Unlock()
+}
+
+// Moves the clock to the time, firing what is due as Advance does.  The
+// clock never moves backwards.
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	if t.After(f.now) {
+		f.setLocked(t)
+	}
+	f.mu.Unlock()
+}
+
+// Moves the clock to the time, forwards or backwards, without firing
+// anything.  Pending timers, tickers and sleepers keep the time they have
+// left to wait.
+func (f *Fake) Jump(t time.Time) {
+	f.mu.Lock()
+	shift := t.Sub(f.now)
+	for _, w := range f.waiters {
+		w.until = w.until.Add(shift)
+	}
+	f.now = t
+	f.notify()
+	f.mu.Unlock()
+}
+
+func (f *Fake) setLocked(t time.Time) {
+	for {
+		sort.SliceStable(f.waiters, func(i, j int) bool {
+			return f.waiters[i].until.Before(f.waiters[j].until)
+		})
+		if len(f.waiters) == 0 || f.waiters[0].until.After(t) {
+			break
+		}
+		w := f.waiters[0]
+		f.now = w.until
+		w.fire()
+	}
+	f.now = t
+	f.notify()
+}
+
+// Returns how many timers, tickers and sleepers are waiting on the clock.
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// Blocks until at least n timers, tickers and sleepers are waiting on the
+// clock, so a test can Advance knowing the code under test is ready.
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	for len(f.waiters) < n {
+		changed := f.changed
+		f.mu.Unlock()
+		<-changed
+		f.mu.Lock()
+	}
+	f.mu.Unlock()
+}
+
+// Must be called with fake.mu held.
+func (f *Fake) removeLocked(w *fakeWaiter) bool {
+	for i, waiting := range f.waiters {
+		if waiting == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeWaiter struct {
+	fake   *Fake
+	c      chan time.Time
+	until  time.Time
+	period time.Duration
+}
+
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.c
+}
+
+// Must be called with fake.mu held.  Drops the tick, like time.Ticker,
+// if the last one has not been received.
+func (w *fakeWaiter) fire() {
+	select {
+	case w.c <- w.until:
+	default:
+	}
+	if w.period > 0 {
+		w.until = w.until.Add(w.period)
+		return
+	}
+	w.fake.removeLocked(w)
+}
+
+func (w *fakeWaiter) Stop() bool {
+	w.fake.mu.Lock()
+	defer w.fake.mu.Unlock()
+	stopped := w.fake.removeLocked(w)
+	w.fake.notify()
+	return stopped
+}
+
+func (w *fakeWaiter) Reset(d time.Duration) bool {
+	f := w.fake
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	active := f.removeLocked(w)
+	if w.period > 0 {
+		w.period = d
+	}
+	w.until = f.now.Add(d)
+	if d <= 0 && w.period == 0 {
+		w.fire()
+	} else {
+		f.waiters = append(f.waiters, w)
+	}
+	f.notify()
+	return active
+}
+
+type fakeTicker struct{ w *fakeWaiter }
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.w.c
+}
+
+func (t *fakeTicker) Stop() {
+	t.w.Stop()
+}
+
+func (t *fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("clock: non-positive interval for Ticker.Reset")
+	}
+	t.w.Reset(d)
+}
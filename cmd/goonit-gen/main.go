@@ -0,0 +1,255 @@
+// Command goonit-gen mocks every exported interface in a package and writes a
+// typed Provider with accessors for each mock, such as Repo returning the
+// same MockRepo on every call and NewRepo returning a new one, so tests get
+// their mocks from one place instead of hand-writing Lazy accessors.
+//
+// Run it from the package with a go:generate annotation like:
+//
+//	//go:generate goonit-gen -out=mocks
+//
+// For each file declaring exported interfaces it runs mockgen in reflect
+// mode on just those interfaces, writing mocks/mock_<file>.go, then writes
+// mocks/provider.go with a Provider interface extending mock.Provider, a
+// BaseProvider implementing it and NewProvider and NewProviderFor
+// constructors.  Pass NewProvider to core.NewWith to get a BaseTest and the
+// typed Provider together.
+//
+// The generated BaseProvider embeds a *mock.BaseProvider, whose Finish
+// verifies every mock it returned.  Extend builds one on an existing
+// provider, sharing its controller, to combine it with other catalogs as
+// shown for mock.Base; name each catalog's struct with -type so they can
+// be embedded side by side.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/sbernheim/goonit/mock"
+)
+
+func main() {
+	out := flag.String("out", "mocks", "directory to write the mocks and provider to")
+	pkg := flag.String("package", "", "package name for the generated files, defaults to the base name of -out")
+	providerFile := flag.String("provider", "provider.go", "name of the generated provider file in -out")
+	typeName := flag.String("type", "BaseProvider", "name of the generated provider struct")
+	mockgen := flag.String("mockgen", "mockgen", "mockgen command to run")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: goonit-gen [flags] [package dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	dir := "."
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	} else if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+	if *pkg == "" {
+		abs, err := filepath.Abs(*out)
+		if err != nil {
+			fatalf("failed to resolve output directory '%s': %s", *out, err.Error())
+		}
+		*pkg = filepath.Base(abs)
+	}
+
+	files, err := findInterfaces(dir)
+	if err != nil {
+		fatalf("failed to scan package '%s': %s", dir, err.Error())
+	}
+	if len(files) == 0 {
+		fatalf("found no exported interfaces in package '%s'", dir)
+	}
+	listed, err := exec.Command("go", "list", "-f", "{{.ImportPath}}", dir).Output()
+	if err != nil {
+		fatalf("failed to find import path of package '%s': %s", dir, err.Error())
+	}
+	importPath := strings.TrimSpace(string(listed))
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		fatalf("failed to create output directory '%s': %s", *out, err.Error())
+	}
+	ifaces := []string{}
+	for _, f := range files {
+		dest := filepath.Join(*out, "mock_"+filepath.Base(f.path))
+		cmd := exec.Command(*mockgen, "-destination="+dest, "-package="+*pkg,
+			importPath, strings.Join(f.interfaces, ","))
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			fatalf("failed to run mockgen for '%s': %s", f.path, err.Error())
+		}
+		ifaces = append(ifaces, f.interfaces...)
+	}
+	sort.Strings(ifaces)
+
+	src, err := providerSource(*pkg, *typeName, importPath, ifaces)
+	if err != nil {
+		fatalf("failed to generate provider: %s", err.Error())
+	}
+	dest := filepath.Join(*out, *providerFile)
+	if err := os.WriteFile(dest, src, 0644); err != nil {
+		fatalf("failed to write provider '%s': %s", dest, err.Error())
+	}
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "goonit-gen: "+format+"\n", args...)
+	os.Exit(1)
+}
+
+// sourceFile is a file in the scanned package and the exported interfaces it
+// declares.
+type sourceFile struct {
+	path       string
+	interfaces []string
+}
+
+// Returns the non-test files in dir declaring exported interfaces, leaving
+// out generic ones, which mockgen cannot mock, and skipping generated files
+// so mocks already in dir aren't mocked again.
+func findInterfaces(dir string) ([]sourceFile, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	files := []sourceFile{}
+	for _, pkg := range pkgs {
+		for path, file := range pkg.Files {
+			if ast.IsGenerated(file) {
+				continue
+			}
+			sf := sourceFile{path: path}
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					if _, ok := ts.Type.(*ast.InterfaceType); ok && ts.Name.IsExported() && ts.TypeParams == nil {
+						sf.interfaces = append(sf.interfaces, ts.Name.Name)
+					}
+				}
+			}
+			if len(sf.interfaces) > 0 {
+				files = append(files, sf)
+			}
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	return files, nil
+}
+
+// accessor is a pair of generated Provider methods returning the mock of an
+// interface: Method, returning the same instance on every call, and New
+// followed by Method, returning a new one.
+type accessor struct {
+	Method    string
+	Interface string
+}
+
+// Names the accessor for each interface after it, adding "Mock" where it or
+// its New counterpart would collide with a method BaseProvider already has
+// or another accessor.
+func accessors(ifaces []string) []accessor {
+	taken := map[string]bool{"Lazy": true}
+	provider := reflect.TypeOf((*mock.BaseProvider)(nil))
+	for i := 0; i < provider.NumMethod(); i++ {
+		taken[provider.Method(i).Name] = true
+	}
+	as := make([]accessor, len(ifaces))
+	for i, iface := range ifaces {
+		method := iface
+		for taken[method] || taken["New"+method] {
+			method += "Mock"
+		}
+		taken[method], taken["New"+method] = true, true
+		as[i] = accessor{Method: method, Interface: iface}
+	}
+	return as
+}
+
+// Returns the provider file for the interfaces, storing each mock under its
+// interface's qualified name so catalogs sharing a BaseProvider don't
+// collide.
+func providerSource(pkg, typeName, importPath string, ifaces []string) ([]byte, error) {
+	var buf bytes.Buffer
+	err := providerTemplate.Execute(&buf, struct {
+		Package    string
+		Type       string
+		ImportPath string
+		Accessors  []accessor
+	}{pkg, typeName, importPath, accessors(ifaces)})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+var providerTemplate = template.Must(template.New("provider").Parse(`// Code generated by goonit-gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+
+	"github.com/sbernheim/goonit/mock"
+)
+
+// Provider is a mock.Provider with accessors for each mock in this package.
+type Provider interface {
+	mock.Provider
+{{- range .Accessors}}
+	{{.Method}}() *Mock{{.Interface}}
+	New{{.Method}}() *Mock{{.Interface}}
+{{- end}}
+}
+
+type {{.Type}} struct {
+	*mock.BaseProvider
+}
+
+func NewProvider(t *testing.T) Provider {
+	return NewProviderFor(t)
+}
+
+func NewProviderFor(t gomock.TestHelper, opts ...mock.Option) Provider {
+	return Extend(mock.NewProviderFor(t, opts...))
+}
+
+// Returns a Provider built on p's mock.BaseProvider, sharing its controller
+// and mocks.
+func Extend(p mock.Provider) *{{.Type}} {
+	return &{{.Type}}{BaseProvider: mock.Base(p)}
+}
+{{- range .Accessors}}
+
+func (p *{{$.Type}}) {{.Method}}() *Mock{{.Interface}} {
+	return p.Lazy("{{$.ImportPath}}.{{.Interface}}", func(c *gomock.Controller) interface{} {
+		return NewMock{{.Interface}}(c)
+	}).(*Mock{{.Interface}})
+}
+
+func (p *{{$.Type}}) New{{.Method}}() *Mock{{.Interface}} {
+	return NewMock{{.Interface}}(p.Controller())
+}
+{{- end}}
+`))
@@ -0,0 +1,238 @@
+package core
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Archive extensions trimmed from the fixture name to name the extraction root.
+var archiveExts = []string{".tar.gz", ".tgz", ".tar", ".zip"}
+
+func (x *BaseTest) extractRoot(archive string) string {
+	name := filepath.Base(archive)
+	for _, ext := range archiveExts {
+		if strings.HasSuffix(strings.ToLower(name), ext) {
+			name = name[:len(name)-len(ext)]
+			break
+		}
+	}
+	return x.TempPath(name)
+}
+
+// Returns the path of the archive entry under root, or an error if the entry
+// would land outside of root.
+func safeJoin(root, name string) (string, error) {
+	target := filepath.Join(root, filepath.FromSlash(name))
+	if target != root && !strings.HasPrefix(target, root+string(filepath.Separator)) {
+		return "", fmt.Errorf("archive entry '%s' escapes the extraction directory", name)
+	}
+	return target, nil
+}
+
+// Returns an error if any path component of target below root, target
+// included, is a symlink, so an entry is never written through a symlink an
+// earlier entry created.
+func checkNoSymlinks(root, target string) error {
+	rel, err := filepath.Rel(root, target)
+	if err != nil {
+		return err
+	}
+	cur := root
+	for _, name := range strings.Split(rel, string(filepath.Separator)) {
+		if name == "." {
+			continue
+		}
+		cur = filepath.Join(cur, name)
+		fi, err := os.Lstat(cur)
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if fi.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("archive entry '%s' would be written through symlink '%s'", target, cur)
+		}
+	}
+	return nil
+}
+
+// Returns an error unless the symlink at target, pointing at linkname,
+// resolves inside root.  Each component of the link is resolved in turn,
+// following symlinks already extracted, so a chain of links that each look
+// harmless cannot step outside root.
+func checkSymlink(root, target, linkname string) error {
+	escapes := fmt.Errorf("archive symlink '%s' points outside the extraction directory", target)
+	if filepath.IsAbs(linkname) {
+		return escapes
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(root, filepath.Dir(target))
+	if err != nil {
+		return err
+	}
+	cur := realRoot
+	for _, name := range append(strings.Split(rel, string(filepath.Separator)), strings.Split(filepath.FromSlash(linkname), string(filepath.Separator))...) {
+		if name == "" || name == "." {
+			continue
+		}
+		cur = filepath.Join(cur, name)
+		if fi, err := os.Lstat(cur); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+			if cur, err = filepath.EvalSymlinks(cur); err != nil {
+				return escapes
+			}
+		}
+		if cur != realRoot && !strings.HasPrefix(cur, realRoot+string(filepath.Separator)) {
+			return escapes
+		}
+	}
+	return nil
+}
+
+func makeDir(root, target string) error {
+	if err := checkNoSymlinks(root, target); err != nil {
+		return err
+	}
+	return os.MkdirAll(target, 0777)
+}
+
+func writeSymlink(root, target, linkname string) error {
+	if err := makeDir(root, filepath.Dir(target)); err != nil {
+		return err
+	}
+	if err := checkNoSymlinks(root, target); err != nil {
+		return err
+	}
+	if err := checkSymlink(root, target, linkname); err != nil {
+		return err
+	}
+	return os.Symlink(linkname, target)
+}
+
+func writeEntry(root, target string, mode os.FileMode, r io.Reader) error {
+	if err := makeDir(root, filepath.Dir(target)); err != nil {
+		return err
+	}
+	if err := checkNoSymlinks(root, target); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, r)
+	return err
+}
+
+func (x *BaseTest) unzip(archive, root string) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		target, err := safeJoin(root, f.Name)
+		if err != nil {
+			return err
+		}
+		if f.FileInfo().IsDir() {
+			if err := makeDir(root, target); err != nil {
+				return err
+			}
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return err
+		}
+		if f.Mode()&os.ModeSymlink != 0 {
+			var linkname []byte
+			if linkname, err = io.ReadAll(r); err == nil {
+				err = writeSymlink(root, target, string(linkname))
+			}
+		} else {
+			err = writeEntry(root, target, f.Mode(), r)
+		}
+		r.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Extracts the zip archive fixture into the temp directory
+// and returns the full path of the extraction root.
+func (x *BaseTest) UnzipToTemp(archive string) string {
+	x.warnNearDeadline("UnzipToTemp", archive)
+	root := x.extractRoot(archive)
+	if err := x.unzip(archive, root); err != nil {
+		x.Fatalf("failed to extract zip archive '%s': %s", archive, err.Error())
+	}
+	return root
+}
+
+func (x *BaseTest) untar(archive, root string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var r io.Reader = bufio.NewReader(f)
+	if magic, err := r.(*bufio.Reader).Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target, err := safeJoin(root, hdr.Name)
+		if err != nil {
+			return err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = makeDir(root, target)
+		case tar.TypeReg:
+			err = writeEntry(root, target, hdr.FileInfo().Mode(), tr)
+		case tar.TypeSymlink:
+			err = writeSymlink(root, target, hdr.Linkname)
+		default:
+			x.Logf("skipping unsupported entry '%s' in tar archive '%s'", hdr.Name, archive)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Extracts the tar archive fixture, optionally gzip compressed, into the temp
+// directory and returns the full path of the extraction root.
+func (x *BaseTest) UntarToTemp(archive string) string {
+	x.warnNearDeadline("UntarToTemp", archive)
+	root := x.extractRoot(archive)
+	if err := x.untar(archive, root); err != nil {
+		x.Fatalf("failed to extract tar archive '%s': %s", archive, err.Error())
+	}
+	return root
+}
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type archiveEntry struct {
+	name     string
+	linkname string
+	body     string
+}
+
+func writeTar(t *testing.T, path string, entries []archiveEntry) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tw := tar.NewWriter(f)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(e.body))}
+		if e.linkname != "" {
+			hdr = &tar.Header{Name: e.name, Mode: 0777, Typeflag: tar.TypeSymlink, Linkname: e.linkname}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeZip(t *testing.T, path string, entries []archiveEntry) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for _, e := range entries {
+		hdr := &zip.FileHeader{Name: e.name}
+		body := e.body
+		hdr.SetMode(0644)
+		if e.linkname != "" {
+			hdr.SetMode(os.ModeSymlink | 0777)
+			body = e.linkname
+		}
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+var archiveAttacks = map[string][]archiveEntry{
+	"symlink chain": {
+		{name: "d", linkname: "."},
+		{name: "d/e", linkname: ".."},
+		{name: "d/e/evil", body: "escaped"},
+	},
+	"resolved link chain": {
+		{name: "d/keep", body: "keep"},
+		{name: "d/e", linkname: ".."},
+		{name: "s", linkname: "d/e"},
+		{name: "f", linkname: "s/.."},
+	},
+	"absolute link": {
+		{name: "abs", linkname: "/etc"},
+	},
+	"parent link": {
+		{name: "up", linkname: "../evil"},
+	},
+	"path traversal": {
+		{name: "../evil", body: "escaped"},
+	},
+}
+
+func testArchiveAttacks(t *testing.T, write func(*testing.T, string, []archiveEntry), extract func(x *BaseTest, archive, root string) error) {
+	for name, entries := range archiveAttacks {
+		t.Run(name, func(t *testing.T) {
+			x := New(t)
+			defer x.Done()
+			dir := t.TempDir()
+			archive := filepath.Join(dir, "attack")
+			write(t, archive, entries)
+			root := filepath.Join(dir, "root")
+			err := extract(x, archive, root)
+			if err == nil {
+				t.Fatalf("extracting %s succeeded", name)
+			}
+			if !strings.Contains(err.Error(), "symlink") && !strings.Contains(err.Error(), "escapes") {
+				t.Fatalf("unexpected error extracting %s: %s", name, err)
+			}
+			if _, err := os.Lstat(filepath.Join(dir, "evil")); !os.IsNotExist(err) {
+				t.Fatalf("extracting %s wrote outside the extraction directory", name)
+			}
+		})
+	}
+}
+
+func TestUntarRejectsEscapes(t *testing.T) {
+	testArchiveAttacks(t, writeTar, (*BaseTest).untar)
+}
+
+func TestUnzipRejectsEscapes(t *testing.T) {
+	testArchiveAttacks(t, writeZip, (*BaseTest).unzip)
+}
+
+func TestUntarKeepsLinksInsideRoot(t *testing.T) {
+	x := New(t)
+	defer x.Done()
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "ok.tar")
+	writeTar(t, archive, []archiveEntry{
+		{name: "data/file", body: "content"},
+		{name: "link", linkname: "data/file"},
+		{name: "data/up", linkname: "../data"},
+	})
+	root := filepath.Join(dir, "root")
+	if err := x.untar(archive, root); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "link"))
+	if err != nil || string(data) != "content" {
+		t.Fatalf("link read %q, %v", data, err)
+	}
+}
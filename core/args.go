@@ -0,0 +1,54 @@
+package core
+
+// Builds an argv that starts with the program name and puts flags ahead of
+// positional arguments, where the flag package expects them.
+type ArgsBuilder struct {
+	x           *BaseTest
+	flags       []string
+	positionals []string
+}
+
+// Returns a builder for the arguments to pass to SetArgs, for example
+// x.BuildArgs().Flag("--verbose").Positional("file").Set().
+func (x *BaseTest) BuildArgs() *ArgsBuilder {
+	return &ArgsBuilder{x: x}
+}
+
+// Adds the flag followed by its values, if any.
+func (b *ArgsBuilder) Flag(name string, vals ...string) *ArgsBuilder {
+	b.flags = append(b.flags, name)
+	b.flags = append(b.flags, vals...)
+	return b
+}
+
+func (b *ArgsBuilder) Positional(args ...string) *ArgsBuilder {
+	b.positionals = append(b.positionals, args...)
+	return b
+}
+
+// Returns the argv, starting with the program name.
+func (b *ArgsBuilder) Build() []string {
+	argv := []string{b.x.commandArg()}
+	argv = append(argv, b.flags...)
+	return append(argv, b.positionals...)
+}
+
+// Passes the argv to SetArgs.
+func (b *ArgsBuilder) Set() *BaseTest {
+	return b.x.SetArgs(b.Build()...)
+}
+
+// Replaces os.Args with the program name followed by the arguments.
+func (x *BaseTest) SetArgsWithCommand(args ...string) *BaseTest {
+	return x.SetArgs(append([]string{x.commandArg()}, args...)...)
+}
+
+// Adds the arguments to the end of the current ones, keeping the program name.
+func (x *BaseTest) AppendArgs(args ...string) *BaseTest {
+	current := x.Args()
+	if len(current) == 0 {
+		current = []string{x.commandArg()}
+	}
+	argv := append([]string{}, current...)
+	return x.SetArgs(append(argv, args...)...)
+}
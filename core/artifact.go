@@ -0,0 +1,57 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Set GOONIT_ARTIFACTS to the directory CI uploads after the run.
+// Artifacts default to goonit-artifacts in the system temp directory.
+const ArtifactsEnv = "GOONIT_ARTIFACTS"
+
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9._/-]+`)
+
+// Returns this test's artifact directory without creating it.  Each level
+// of subtest is a directory, and any named "." or ".." is renamed "_" so
+// the directory stays under the artifacts root.
+func (x *BaseTest) artifactDir() string {
+	root := os.Getenv(ArtifactsEnv)
+	if root == "" {
+		root = filepath.Join(os.TempDir(), "goonit-artifacts")
+	}
+	elems := strings.Split(unsafePathChars.ReplaceAllString(x.t.Name(), "_"), "/")
+	for i, elem := range elems {
+		if elem == "." || elem == ".." {
+			elems[i] = "_"
+		}
+	}
+	return filepath.Join(append([]string{root}, elems...)...)
+}
+
+// Returns the directory for this test's artifacts, creating it if needed.
+// Unlike the temp directory it is never removed, so CI can upload logs,
+// profiles and screenshots saved there after the tests finish.
+func (x *BaseTest) ArtifactDir() string {
+	dir := x.artifactDir()
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		x.Fatalf("failed to create artifact directory '%s': %s", dir, err.Error())
+	}
+	x.usedArtifacts = true
+	return dir
+}
+
+// Returns the full path for the named artifact in this test's artifact
+// directory.  Fails the test if the name leads out of the directory.
+func (x *BaseTest) ArtifactPath(name string) string {
+	dir := x.ArtifactDir()
+	path, err := safeJoin(filepath.Clean(dir), name)
+	if err != nil {
+		x.Fatalf("failed to place artifact '%s': it escapes the artifact directory '%s'", name, dir)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		x.Fatalf("failed to create artifact directory for '%s': %s", name, err.Error())
+	}
+	return path
+}
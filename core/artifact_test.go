@@ -0,0 +1,45 @@
+package core
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func underRoot(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func TestArtifactDirStaysUnderRootForDotDotSubtests(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv(ArtifactsEnv, root)
+	t.Run("..", func(t *testing.T) {
+		t.Run("..", func(t *testing.T) {
+			x := New(t, WithoutMocks())
+			if dir := x.ArtifactDir(); !underRoot(root, dir) {
+				t.Errorf("artifact directory '%s' is outside '%s'", dir, root)
+			}
+		})
+	})
+}
+
+func TestArtifactPathRejectsEscapingNames(t *testing.T) {
+	t.Setenv(ArtifactsEnv, t.TempDir())
+	lt := &lookalikeT{T: t}
+	x := NewT(lt, WithoutMocks())
+	if path := x.ArtifactPath("logs/run.log"); !underRoot(x.ArtifactDir(), path) {
+		t.Errorf("artifact path '%s' is outside the artifact directory", path)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != nil && r != (lookalikeFatal{}) {
+				panic(r)
+			}
+		}()
+		x.ArtifactPath("../../escaped.log")
+	}()
+	if !lt.logged("escapes the artifact directory") {
+		t.Errorf("escaping name was not rejected; logged %v", lt.logs)
+	}
+}
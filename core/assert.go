@@ -0,0 +1,43 @@
+package core
+
+import (
+	"github.com/google/go-cmp/cmp"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+
+	"github.com/sbernheim/goonit/diff"
+)
+
+// Starts an assertion that stops the test immediately when it fails,
+// for preconditions the rest of the test depends on.
+func (x *BaseTest) Require(actual interface{}, extra ...interface{}) types.Assertion {
+	return NewGomega(func(message string, _ ...int) {
+		x.t.Helper()
+		x.t.Fatalf("\n%s", message)
+	}).Expect(actual, extra...)
+}
+
+// Starts an assertion that marks the test failed when it fails
+// but lets the test continue.
+func (x *BaseTest) Check(actual interface{}, extra ...interface{}) types.Assertion {
+	return NewGomega(func(message string, _ ...int) {
+		x.t.Helper()
+		x.t.Errorf("\n%s", message)
+	}).Expect(actual, extra...)
+}
+
+// Fails the test with a field level diff unless actual equals expected.
+// Options such as cmpopts.IgnoreFields and cmp.Comparer control the comparison.
+// The diff shown is go-cmp's unless another is set with SetDiff or
+// diff.SetDefault.
+func (x *BaseTest) ExpectEqual(actual, expected interface{}, opts ...cmp.Option) {
+	if cmp.Equal(expected, actual, opts...) {
+		return
+	}
+	x.t.Helper()
+	d := x.diffOr(diff.Cmp(opts...)).Diff(expected, actual)
+	if d == "" {
+		d = diff.Cmp(opts...).Diff(expected, actual)
+	}
+	x.t.Fatalf("values are not equal:\n%s", d)
+}
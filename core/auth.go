@@ -0,0 +1,35 @@
+package core
+
+import (
+	"github.com/sbernheim/goonit/httpx"
+)
+
+// Returns the test's JWT issuer, whose ephemeral key is generated on
+// first use.
+func (x *BaseTest) JWTIssuer() *httpx.Issuer {
+	if x.jwtIssuer == nil {
+		x.jwtIssuer = httpx.NewIssuer(x.t)
+	}
+	return x.jwtIssuer
+}
+
+// Returns a JWT with the claims signed by the test's JWTIssuer.
+func (x *BaseTest) SignedJWT(claims map[string]interface{}, opts ...httpx.JWTOption) string {
+	return x.JWTIssuer().Sign(claims, opts...)
+}
+
+// Starts an HTTP server with a JWKS endpoint for the test's JWTIssuer,
+// shut down when the test is Done, and returns the JWKS URL.
+func (x *BaseTest) JWKSServer() string {
+	s := x.HTTPServer()
+	return x.JWTIssuer().ServeJWKS(s)
+}
+
+// Starts an OAuth2 token endpoint issuing tokens from the test's
+// JWTIssuer, shut down when the test is Done.
+func (x *BaseTest) OAuth2Server() *httpx.OAuth2Server {
+	s := httpx.NewOAuth2Server(x.t, x.JWTIssuer())
+	x.DebugInfo("OAuth2 token endpoint", s.TokenURL())
+	x.DoAfter(s.Close)
+	return s
+}
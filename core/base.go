@@ -1,13 +1,20 @@
 package core
 
 import (
+	"encoding/hex"
 	"fmt"
+	"io"
+	"io/fs"
 	"io/ioutil"
+	"math/rand"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
@@ -16,6 +23,11 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/matchers"
 
+	"github.com/sbernheim/goonit/clock"
+	"github.com/sbernheim/goonit/diff"
+	"github.com/sbernheim/goonit/fake"
+	"github.com/sbernheim/goonit/httpx"
+	"github.com/sbernheim/goonit/match"
 	"github.com/sbernheim/goonit/mock"
 )
 
@@ -30,42 +42,127 @@ type Test interface {
 	DoAfter(doAfterFunc func())
 	SetEnv(name, val string) *BaseTest
 	SetEnvs(namesAndValues ...string) *BaseTest
+	SetEnvMap(envs map[string]string) *BaseTest
+	SetEnvStruct(config interface{}) *BaseTest
 	SetArgs(args ...string) *BaseTest
+	SetArgsWithCommand(args ...string) *BaseTest
+	AppendArgs(args ...string) *BaseTest
 	TempDir() string
 	TempPath(filename string) string
+	TempFS() fs.FS
+	TempWriteFS() WriteFS
 	CopyToTempFile(srcFilepath, destFile string) string
 	CopyToTemp(srcFilepath string) string
+	CopyDirToTemp(srcDir string) string
+	CopyFSToTemp(fsys fs.FS, root string) string
+	ExpectChecksum(path, alg, hexDigest string)
+	UnzipToTemp(archive string) string
+	UntarToTemp(archive string) string
 	ErrFor(errFor string) error
+	Deadline() (time.Time, bool)
+	RemainingTime() time.Duration
 	Done()
 }
 
 type BaseTest struct {
 	WithT
-	t            *testing.T
-	TestFunc     *runtime.Func
-	mockProvider mock.Provider
-	testLogr     testlogr.TestLogger
-	mockLogr     *mock.MockLogger
-	logger       logr.Logger
-	captured     []interface{}
-	capsFrom     map[string][]interface{}
-	tempDir      string
-	args         []string
-	afterFunc    func()
-}
-
-func New(t *testing.T) *BaseTest {
-	mockProvider := mock.NewProvider(t)
-	return &BaseTest{
-		WithT:        *NewWithT(t),
-		t:            t,
-		mockProvider: mockProvider,
-		testLogr:     testlogr.TestLogger{T: t},
-		mockLogr:     mockProvider.Logger(),
-		afterFunc:    func() { mockProvider.Finish() },
-		captured:     []interface{}{},
-		capsFrom:     map[string][]interface{}{},
+	t                 T
+	TestFunc          *runtime.Func
+	mockProvider      mock.Provider
+	testLogr          logr.Logger
+	mockLogr          *mock.MockLogger
+	logger            logr.Logger
+	captured          []interface{}
+	capsFrom          map[string][]interface{}
+	returned          []Returned
+	tempDir           string
+	args              []string
+	envs              map[string]string
+	propagated        []string
+	isolatedEnv       bool
+	fixtureTags       []string
+	tenants           map[string]*Tenant
+	startedAt         time.Time
+	warmUp            time.Duration
+	scenario          string
+	expectations      []string
+	failureHooks      []func()
+	failureHooksRan   bool
+	afterFunc         func()
+	afterFrom         []string
+	uploaders         []Uploader
+	usedArtifacts     bool
+	rand              *rand.Rand
+	metrics           *Metrics
+	fake              *fake.Faker
+	dbTrackers        []*DBTracker
+	eventuallyTimeout time.Duration
+	eventuallyPoll    time.Duration
+	seed              int64
+	seeded            bool
+	fakeClock         *clock.Fake
+	differ            diff.Diff
+	exitTrap          *exitTrap
+	debugInfo         [][2]string
+	debugPaused       bool
+	signalTrap        *signalTrap
+	certs             int
+	jwtIssuer         *httpx.Issuer
+	capMu             sync.Mutex
+	mockCallsMu       sync.Mutex
+	unexpected        []mock.UnexpectedCall
+	mockCalls         []MockCall
+	goroutineChecks   []*GoroutineCheck
+}
+
+func New(t *testing.T, opts ...Option) *BaseTest {
+	return newBaseTest(t, testlogr.TestLogger{T: t}, opts)
+}
+
+// Creates a BaseTest that reports through a testing.T lookalike, such as
+// Ginkgo's GinkgoT(), in place of a *testing.T.
+func NewT(t T, opts ...Option) *BaseTest {
+	if tt, ok := t.(*testing.T); ok {
+		return New(tt, opts...)
+	}
+	return newBaseTest(t, &tLogger{t: t}, opts)
+}
+
+// Returns the T the BaseTest reports through, for helpers in other packages
+// that take a T.
+func (x *BaseTest) TestingT() T {
+	return x.t
+}
+
+func newBaseTest(t T, testLogr logr.Logger, opts []Option) *BaseTest {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	x := &BaseTest{
+		WithT:       *NewWithT(t),
+		t:           t,
+		testLogr:    testLogr,
+		logger:      o.logger,
+		tempDir:     o.tempDir,
+		isolatedEnv: o.isolatedEnv,
+		uploaders:   o.uploaders,
+		startedAt:   time.Now(),
+		afterFunc:   func() {},
+		captured:    []interface{}{},
+		capsFrom:    map[string][]interface{}{},
+	}
+	if !o.withoutMocks {
+		mockProvider := o.provider
+		if mockProvider == nil {
+			mockProvider = mock.NewProviderFor(t, mock.WithReporter(&mockReporter{x: x}))
+		}
+		x.mockProvider = mockProvider
+		x.mockLogr = mockProvider.Logger()
+		x.afterFunc = func() { mockProvider.Finish() }
 	}
+	t.Cleanup(x.runFailureHooks)
+	return x
 }
 
 func (x *BaseTest) Logf(format string, args ...interface{}) {
@@ -91,6 +188,9 @@ func (x *BaseTest) TestLogr() logr.Logger {
 	return x.testLogr
 }
 
+// Returns the provider's MockLogger, the same instance x.Mock().Logger()
+// returns, so expectations set on it apply to the logger injected into the
+// code under test.
 func (x *BaseTest) MockLogr() *mock.MockLogger {
 	return x.mockLogr
 }
@@ -99,7 +199,29 @@ func (x *BaseTest) Mock() mock.Provider {
 	return x.mockProvider
 }
 
+// Verifies the mocks' expectations and starts over with new mocks, for
+// table-driven tests that reuse one BaseTest for every row.  Calls recorded
+// for Verify and UnexpectedCalls are cleared too; captures and the fake
+// Clock are kept.  Build whatever the mocks were injected into again for
+// each row: it still holds the old mocks, which fail the test when called.
+func (x *BaseTest) ResetMocks() *BaseTest {
+	if x.mockProvider == nil {
+		x.Fatalf("ResetMocks needs mocks, but the test was created WithoutMocks")
+	}
+	p, ok := x.mockProvider.(interface{ Reset() })
+	if !ok {
+		x.Fatalf("ResetMocks needs a provider with Reset, such as one built on mock.BaseProvider, but the test's is a %T", x.mockProvider)
+	}
+	p.Reset()
+	x.mockLogr = x.mockProvider.Logger()
+	x.mockCallsMu.Lock()
+	x.mockCalls, x.unexpected = nil, nil
+	x.mockCallsMu.Unlock()
+	return x
+}
+
 func (x *BaseTest) DoAfter(doAfterFunc func()) {
+	x.afterFrom = append(x.afterFrom, x.callerLogString())
 	f := x.afterFunc
 	x.afterFunc = func() {
 		f()
@@ -107,6 +229,18 @@ func (x *BaseTest) DoAfter(doAfterFunc func()) {
 	}
 }
 
+// Registers the function to run when the test is Done ahead of those
+// already registered, for teardowns that need something set up earlier in
+// the test.
+func (x *BaseTest) doAfterFirst(doAfterFunc func()) {
+	x.afterFrom = append(x.afterFrom, x.callerLogString())
+	f := x.afterFunc
+	x.afterFunc = func() {
+		doAfterFunc()
+		f()
+	}
+}
+
 func (x *BaseTest) restoreExistingEnvAfter(name string) {
 	currentVal, ok := os.LookupEnv(name)
 	if ok {
@@ -120,7 +254,20 @@ func (x *BaseTest) restoreExistingEnvAfter(name string) {
 	}
 }
 
+// Sets the environment variable until the test is Done.  Parallel tests
+// cannot share the process environment, so SetEnv fails them unless the
+// BaseTest was created WithIsolatedEnv.
 func (x *BaseTest) SetEnv(name, val string) *BaseTest {
+	if x.envs == nil {
+		x.envs = map[string]string{}
+	}
+	x.envs[name] = val
+	if x.isolatedEnv {
+		return x
+	}
+	if err := x.guardProcessState(name); err != nil {
+		x.Fatalf("SetEnv(%q) %s", name, err.Error())
+	}
 	x.restoreExistingEnvAfter(name)
 	os.Setenv(name, val)
 	return x
@@ -163,10 +310,18 @@ func (x *BaseTest) restoreExistingArgsAfter() {
 	}
 }
 
+// Replaces os.Args until the test is Done, or only the value Args returns
+// when the BaseTest was created WithIsolatedEnv.
 func (x *BaseTest) SetArgs(args ...string) *BaseTest {
-	x.restoreExistingArgsAfter()
 	x.args = []string{}
 	x.args = append(x.args, args...)
+	if x.isolatedEnv {
+		return x
+	}
+	if err := x.guardProcessState(argsGuardEnv); err != nil {
+		x.Fatalf("SetArgs %s", err.Error())
+	}
+	x.restoreExistingArgsAfter()
 	os.Args = x.args
 	return x
 }
@@ -174,6 +329,16 @@ func (x *BaseTest) SetArgs(args ...string) *BaseTest {
 func (x *BaseTest) TempDir() string {
 	if x.tempDir == "" {
 		x.tempDir = x.t.TempDir()
+		x.t.Cleanup(x.runFailureHooks)
+	}
+	if x.tempDir == "" {
+		// Some testing.T lookalikes don't manage temp directories.
+		dir, err := ioutil.TempDir("", "goonit")
+		if err != nil {
+			x.Fatalf("failed to create temp directory: %s", err.Error())
+		}
+		x.tempDir = dir
+		x.DoAfter(func() { os.RemoveAll(dir) })
 	}
 	return x.tempDir
 }
@@ -182,15 +347,29 @@ func (x *BaseTest) TempPath(filename string) string {
 	return filepath.Join(x.TempDir(), filename)
 }
 
+// Streams the source file to dest and gives the copy the source file's mode bits.
 func (x *BaseTest) copyFile(src, dest string) {
-	data, err := ioutil.ReadFile(src)
+	in, err := os.Open(src)
 	if err != nil {
 		x.Fatalf("failed to read file '%s': %s", src, err.Error())
 	}
-	err = ioutil.WriteFile(dest, data, 0666)
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		x.Fatalf("failed to stat file '%s': %s", src, err.Error())
+	}
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
 	if err != nil {
 		x.Fatalf("failed to write file '%s': %s", dest, err.Error())
 	}
+	defer out.Close()
+	if _, err = io.Copy(out, in); err != nil {
+		x.Fatalf("failed to write file '%s': %s", dest, err.Error())
+	}
+	// The umask may have masked bits passed to OpenFile.
+	if err = out.Chmod(info.Mode().Perm()); err != nil {
+		x.Fatalf("failed to set mode of file '%s': %s", dest, err.Error())
+	}
 }
 
 // Copies the source file to a file in temp directory with the provided name
@@ -207,38 +386,193 @@ func (x *BaseTest) CopyToTemp(srcFilepath string) string {
 	return x.CopyToTempFile(srcFilepath, filepath.Base(srcFilepath))
 }
 
+func (x *BaseTest) copyDir(src, dest string) {
+	dirModes := map[string]os.FileMode{}
+	dirs := []string{}
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.IsDir():
+			// Directories stay writable until every entry has been copied.
+			dirModes[target] = info.Mode().Perm()
+			dirs = append(dirs, target)
+			return os.MkdirAll(target, 0700)
+		default:
+			x.copyFile(path, target)
+			return nil
+		}
+	})
+	if err != nil {
+		x.Fatalf("failed to copy directory '%s': %s", src, err.Error())
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chmod(dirs[i], dirModes[dirs[i]]); err != nil {
+			x.Fatalf("failed to set mode of directory '%s': %s", dirs[i], err.Error())
+		}
+	}
+}
+
+// Recursively copies the source directory to the temp directory, preserving
+// its structure, permissions and symlinks, and returns the full path of the copy.
+func (x *BaseTest) CopyDirToTemp(srcDir string) string {
+	x.warnNearDeadline("CopyDirToTemp", srcDir)
+	destDir := x.TempPath(filepath.Base(filepath.Clean(srcDir)))
+	x.copyDir(srcDir, destDir)
+	return destDir
+}
+
+func (x *BaseTest) copyFS(fsys fs.FS, root, dest string) {
+	err := fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := dest
+		if name != root {
+			rel := name
+			if root != "." {
+				rel = strings.TrimPrefix(name, root+"/")
+			}
+			target = filepath.Join(dest, filepath.FromSlash(rel))
+		}
+		if d.IsDir() {
+			return os.MkdirAll(target, 0777)
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(target, data, 0666)
+	})
+	if err != nil {
+		x.Fatalf("failed to copy '%s' from file system: %s", root, err.Error())
+	}
+}
+
+// Copies the tree rooted at root in the file system, such as an embed.FS,
+// to the temp directory and returns the full path of the copy.
+// A root of "." copies the whole file system into the temp directory itself.
+func (x *BaseTest) CopyFSToTemp(fsys fs.FS, root string) string {
+	x.warnNearDeadline("CopyFSToTemp", root)
+	destDir := x.TempDir()
+	if root != "." {
+		destDir = x.TempPath(path.Base(root))
+	}
+	x.copyFS(fsys, root, destDir)
+	return destDir
+}
+
+// Fails the test unless the file at path has the hex-encoded digest
+// using the named hash algorithm: md5, sha1, sha256 or sha512.
+func (x *BaseTest) ExpectChecksum(path, alg, hexDigest string) {
+	h, err := match.NewHash(alg)
+	if err != nil {
+		x.Fatalf("cannot checksum '%s': %s", path, err.Error())
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		x.Fatalf("failed to open file '%s': %s", path, err.Error())
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		x.Fatalf("failed to read file '%s': %s", path, err.Error())
+	}
+	x.Expect(hex.EncodeToString(h.Sum(nil))).Should(Equal(strings.ToLower(hexDigest)), "%s checksum of file '%s'", alg, path)
+}
+
 func (x *BaseTest) ErrFor(errFor string) error {
 	return fmt.Errorf("this is a test-generated error for '%s'", errFor)
 }
 
 func (x *BaseTest) Done() {
+	// Testing.T lookalikes such as GinkgoT() may not run cleanups, so
+	// Done runs the failure hooks too, even if a check below stops the test.
+	defer x.runFailureHooks()
+	if x.exitTrap != nil {
+		// A trapped exit on the test goroutine unwinds to here.
+		if r := recover(); r != nil {
+			if _, exited := r.(exitCalled); !exited {
+				panic(r)
+			}
+		}
+	}
+	x.pauseForDebugger()
 	x.afterFunc()
+	x.uploadArtifacts()
+	for _, c := range x.goroutineChecks {
+		c.verify()
+	}
 }
 
 func (x *BaseTest) Capture(captured ...interface{}) *BaseTest {
 	stack := x.BuildCallerStack()
 	if stack.Mocked == nil {
 		x.Logf("NO MOCK FOUND FOR CAPTURE from %s", stack.Caller.LogString())
-	} else {
-		caps, found := x.capsFrom[stack.MockedCall()]
-		if !found {
-			caps = make([]interface{}, 0, 3)
-		}
-		x.capsFrom[stack.MockedCall()] = append(caps, captured...)
+		x.capMu.Lock()
+		x.captured = append(x.captured, captured...)
+		x.capMu.Unlock()
+		return x
 	}
+	return x.captureFrom(stack.MockedCall(), captured...)
+}
+
+// Captures the values under the key, such as a gRPC method name, for
+// CapturedFrom to return, for helpers capturing calls that aren't mock
+// calls.  Safe to call from any goroutine.
+func (x *BaseTest) CaptureAs(key string, captured ...interface{}) *BaseTest {
+	return x.captureFrom(key, captured...)
+}
+
+// Captures the values under the key CapturedFrom looks them up by.  Safe to
+// call from the goroutines of servers under test.
+func (x *BaseTest) captureFrom(key string, captured ...interface{}) *BaseTest {
+	x.capMu.Lock()
+	defer x.capMu.Unlock()
+	caps, found := x.capsFrom[key]
+	if !found {
+		caps = make([]interface{}, 0, 3)
+	}
+	x.capsFrom[key] = append(caps, captured...)
 	x.captured = append(x.captured, captured...)
 	return x
 }
 
 func (x *BaseTest) AllCaptured() []interface{} {
-	return x.captured
+	x.capMu.Lock()
+	defer x.capMu.Unlock()
+	return append([]interface{}{}, x.captured...)
+}
+
+// Returns a copy of the captures by key, taken under the lock captureFrom
+// holds.
+func (x *BaseTest) allCapsFrom() map[string][]interface{} {
+	x.capMu.Lock()
+	defer x.capMu.Unlock()
+	capsFrom := make(map[string][]interface{}, len(x.capsFrom))
+	for key, caps := range x.capsFrom {
+		capsFrom[key] = append([]interface{}{}, caps...)
+	}
+	return capsFrom
 }
 
 func (x *BaseTest) Captured(index int, expectTypeOf interface{}) interface{} {
-	x.Expect(x.captured).ShouldNot(BeEmpty(), "There were no captured parameter values!")
-	x.Expect(len(x.captured)).Should(BeNumerically(">=", index+1), "There were only %d captured parameter values - cannot retrieve index %d", len(x.captured), index)
-	x.Expect(x.captured[index]).Should(BeAssignableToTypeOf(expectTypeOf), "Captured parameter type %T at index %d is not assignable to type %T", x.captured[index], index, expectTypeOf)
-	return x.captured[index]
+	captured := x.AllCaptured()
+	x.Expect(captured).ShouldNot(BeEmpty(), "There were no captured parameter values!")
+	x.Expect(len(captured)).Should(BeNumerically(">=", index+1), "There were only %d captured parameter values - cannot retrieve index %d", len(captured), index)
+	x.Expect(captured[index]).Should(BeAssignableToTypeOf(expectTypeOf), "Captured parameter type %T at index %d is not assignable to type %T", captured[index], index, expectTypeOf)
+	return captured[index]
 }
 
 func (x *BaseTest) capturedOfType(expectTypeOf interface{}, caps []interface{}) []interface{} {
@@ -254,7 +588,7 @@ func (x *BaseTest) capturedOfType(expectTypeOf interface{}, caps []interface{})
 
 func (x *BaseTest) CapturedOfType(expectTypeOf interface{}) []interface{} {
 	caps := make([]interface{}, 0, 1)
-	for _, callCaps := range x.capsFrom {
+	for _, callCaps := range x.allCapsFrom() {
 		caps = append(caps, x.capturedOfType(expectTypeOf, callCaps)...)
 	}
 	if len(caps) == 0 {
@@ -269,15 +603,16 @@ func (x *BaseTest) FirstCapturedOfType(expectTypeOf interface{}) interface{} {
 
 func (x *BaseTest) capturedKeys() []string {
 	keys := make([]string, 0, 1)
-	for key := range x.capsFrom {
+	for key := range x.allCapsFrom() {
 		keys = append(keys, key)
 	}
 	return keys
 }
 
 func (x *BaseTest) CapturedFrom(mockCall string) []interface{} {
-	x.Expect(x.capsFrom).ShouldNot(BeEmpty(), "There were no captured parameter values!")
-	caps, found := x.capsFrom[mockCall]
+	capsFrom := x.allCapsFrom()
+	x.Expect(capsFrom).ShouldNot(BeEmpty(), "There were no captured parameter values!")
+	caps, found := capsFrom[mockCall]
 	if !found {
 		caller := x.GetCallerInfo().LogString()
 		x.Fatalf("at %s there were no captures from mock call '%s'!  keys %v", caller, mockCall, x.capturedKeys())
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"reflect"
+	"sync"
+)
+
+var (
+	buildersMu sync.Mutex
+	builders   = map[reflect.Type]interface{}{}
+)
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Registers the builder for a domain fixture type, typically from an init
+// function in a shared test package.  Registering a type again replaces it.
+func RegisterBuilder[T any](builder func(x *BaseTest) T) {
+	buildersMu.Lock()
+	defer buildersMu.Unlock()
+	builders[typeOf[T]()] = builder
+}
+
+// Builds a T with its registered builder and applies the mutators to it,
+// so each test overrides only the fields it cares about.
+// Go methods cannot take type parameters, so this is a function of the test:
+//
+//	user := core.Build(x, func(u *User) { u.Admin = true })
+func Build[T any](x *BaseTest, mutators ...func(*T)) T {
+	buildersMu.Lock()
+	builder, found := builders[typeOf[T]()]
+	buildersMu.Unlock()
+	if !found {
+		x.Fatalf("no builder registered for %s", typeOf[T]())
+	}
+	v := builder.(func(x *BaseTest) T)(x)
+	for _, mutate := range mutators {
+		mutate(&v)
+	}
+	return v
+}
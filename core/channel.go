@@ -0,0 +1,68 @@
+package core
+
+import (
+	"reflect"
+	"time"
+)
+
+func (x *BaseTest) receiveChan(helper string, ch interface{}) reflect.Value {
+	v := reflect.ValueOf(ch)
+	if v.Kind() != reflect.Chan || v.Type().ChanDir()&reflect.RecvDir == 0 {
+		x.Fatalf("%s needs a channel it can receive from, passed %T", helper, ch)
+	}
+	return v
+}
+
+// Waits up to within for a value from the channel, which may be of any
+// element type.  Fails the test if nothing is received in time or the
+// channel is closed.  Returns the value received.
+func (x *BaseTest) ExpectReceive(ch interface{}, within time.Duration) interface{} {
+	chosen, value, ok := x.selectWithin(x.receiveChan("ExpectReceive", ch), within)
+	if chosen == 1 {
+		x.Fatalf("expected to receive from %T within %s but received nothing", ch, within)
+	}
+	if !ok {
+		x.Fatalf("expected to receive from %T but it was closed", ch)
+	}
+	return value.Interface()
+}
+
+// Fails the test if a value is received from the channel, or it is closed,
+// within the duration.
+func (x *BaseTest) ExpectNoReceive(ch interface{}, within time.Duration) {
+	chosen, value, ok := x.selectWithin(x.receiveChan("ExpectNoReceive", ch), within)
+	if chosen == 1 {
+		return
+	}
+	if !ok {
+		x.Fatalf("expected to receive nothing from %T within %s but it was closed", ch, within)
+	}
+	x.Fatalf("expected to receive nothing from %T within %s but received %v", ch, within, value.Interface())
+}
+
+// Fails the test unless the channel is closed within the duration.  Values
+// still buffered in the channel are drained; receiving one is not a failure.
+func (x *BaseTest) ExpectClosed(ch interface{}, within time.Duration) {
+	v := x.receiveChan("ExpectClosed", ch)
+	deadline := time.Now().Add(within)
+	for {
+		chosen, _, ok := x.selectWithin(v, time.Until(deadline))
+		if chosen == 1 {
+			x.Fatalf("expected %T to be closed within %s", ch, within)
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+// Receives from the channel or times out, returning 0 and the value
+// received, or 1 on timeout.
+func (x *BaseTest) selectWithin(v reflect.Value, within time.Duration) (int, reflect.Value, bool) {
+	timer := time.NewTimer(within)
+	defer timer.Stop()
+	return reflect.Select([]reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: v},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(timer.C)},
+	})
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"time"
+
+	"github.com/sbernheim/goonit/clock"
+)
+
+// Returns the test's fake clock.  It is the same clock the mock provider's
+// Clock returns, if it has one, so code under test built from the provider
+// shares it.
+func (x *BaseTest) FakeClock() *clock.Fake {
+	if p, ok := x.mockProvider.(interface{ Clock() *clock.Fake }); ok {
+		return p.Clock()
+	}
+	if x.fakeClock == nil {
+		x.fakeClock = clock.NewFake(time.Now())
+	}
+	return x.fakeClock
+}
+
+// Stops the test's fake clock at the time, which can be in the past.
+// Time stays there until TimeTravel, or the code under test's timers are
+// fired by advancing the clock.
+func (x *BaseTest) FreezeTime(at time.Time) *clock.Fake {
+	c := x.FakeClock()
+	c.Jump(at)
+	return c
+}
+
+// Moves the test's fake clock forward by the duration, firing the timers
+// due on the way.  Returns the new time.
+func (x *BaseTest) TimeTravel(d time.Duration) time.Time {
+	c := x.FakeClock()
+	c.Advance(d)
+	return c.Now()
+}
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/golang/mock/gomock"
+)
+
+// ContextAudit records which of a set of expected context keys were present
+// on each mocked call, to catch context values dropped between layers.
+//
+// Pass Context in place of a mock expectation's context argument to audit
+// every call matching it:
+//
+//	audit := x.AuditContext(traceIDKey, principalKey)
+//	repo.EXPECT().Get(audit.Context(), id).Return(user, nil)
+//
+// or call Record with the context from a DoAndReturn, or any function a mock
+// calls.  Calls are named the same way Capture names them.
+type ContextAudit struct {
+	x       *BaseTest
+	keys    []interface{}
+	mu      sync.Mutex
+	calls   []string
+	seen    [][]bool
+	lastCtx context.Context
+}
+
+// Returns a ContextAudit that checks every recorded context for the keys.
+func (x *BaseTest) AuditContext(keys ...interface{}) *ContextAudit {
+	return &ContextAudit{x: x, keys: keys}
+}
+
+// Records which expected keys have values in the context passed to the mocked call.
+func (a *ContextAudit) Record(ctx context.Context) *ContextAudit {
+	a.record(ctx, false)
+	return a
+}
+
+// Returns a matcher for a mocked call's context argument that matches any
+// context, nil included, and records which expected keys it carries.
+// gomock may try one call against several expectations of its method, so a
+// context matched again by the same mocked call straight after is recorded
+// once.
+func (a *ContextAudit) Context() gomock.Matcher {
+	return &contextMatcher{audit: a}
+}
+
+type contextMatcher struct {
+	audit *ContextAudit
+}
+
+func (m *contextMatcher) Matches(x interface{}) bool {
+	ctx, ok := x.(context.Context)
+	if !ok && x != nil {
+		return false
+	}
+	m.audit.record(ctx, true)
+	return true
+}
+
+func (m *contextMatcher) String() string {
+	names := make([]string, len(m.audit.keys))
+	for i, key := range m.audit.keys {
+		names[i] = keyName(key)
+	}
+	return fmt.Sprintf("is a context audited for %s", strings.Join(names, ", "))
+}
+
+// Records the keys present in the context for the mocked call on the
+// stack, skipping the context if dedupe is set and the last record was the
+// same context on the same call.
+func (a *ContextAudit) record(ctx context.Context, dedupe bool) {
+	stack := a.x.BuildCallerStack()
+	call := "unknown call"
+	if stack.Mocked != nil && stack.Mocker != nil {
+		call = stack.MockedCall()
+	} else if stack.Caller != nil {
+		call = stack.Caller.LogString()
+	}
+	present := make([]bool, len(a.keys))
+	for i, key := range a.keys {
+		present[i] = ctx != nil && ctx.Value(key) != nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if dedupe && len(a.calls) > 0 && a.calls[len(a.calls)-1] == call && sameContext(a.lastCtx, ctx) {
+		return
+	}
+	a.calls = append(a.calls, call)
+	a.seen = append(a.seen, present)
+	a.lastCtx = ctx
+}
+
+func sameContext(a, b context.Context) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return reflect.TypeOf(a) == reflect.TypeOf(b) && reflect.TypeOf(a).Comparable() && a == b
+}
+
+func keyName(key interface{}) string {
+	if s, ok := key.(fmt.Stringer); ok {
+		return s.String()
+	}
+	if name := fmt.Sprintf("%v", key); name != "" && name != "{}" {
+		return name
+	}
+	return fmt.Sprintf("%T", key)
+}
+
+// Returns a table of recorded calls against expected keys, marking each key
+// as present (+) or missing (-) on each call.
+func (a *ContextAudit) Matrix() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	b := &strings.Builder{}
+	b.WriteString("call")
+	for _, key := range a.keys {
+		fmt.Fprintf(b, "\t%s", keyName(key))
+	}
+	for i, call := range a.calls {
+		fmt.Fprintf(b, "\n%s", call)
+		for _, present := range a.seen[i] {
+			mark := "-"
+			if present {
+				mark = "+"
+			}
+			fmt.Fprintf(b, "\t%s", mark)
+		}
+	}
+	return b.String()
+}
+
+func (a *ContextAudit) missing() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	count := 0
+	for _, present := range a.seen {
+		for _, p := range present {
+			if !p {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// Fails the test if no calls were recorded or any recorded call was missing
+// one of the expected keys, reporting the per-call matrix.
+func (a *ContextAudit) ExpectPropagated() {
+	a.mu.Lock()
+	calls := len(a.calls)
+	a.mu.Unlock()
+	if calls == 0 {
+		a.x.Fatalf("no context was recorded for keys %v", a.keys)
+	}
+	if missing := a.missing(); missing > 0 {
+		a.x.Fatalf("%d context values were missing from mocked calls:\n%s", missing, a.Matrix())
+	}
+}
@@ -0,0 +1,30 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type auditKey string
+
+func TestAuditContextMatcherRecordsEachMatchedCallOnce(t *testing.T) {
+	x := New(t)
+	l := x.MockLogr()
+	audit := x.AuditContext(auditKey("trace"))
+	l.EXPECT().Info("handled", audit.Context(), "a")
+	l.EXPECT().Info("handled", audit.Context(), "b").Times(2)
+	traced := context.WithValue(context.Background(), auditKey("trace"), "t-1")
+	l.Info("handled", traced, "b")
+	l.Info("handled", context.Background(), "b")
+	l.Info("handled", traced, "a")
+	rows := strings.Split(audit.Matrix(), "\n")
+	if len(rows) != 4 {
+		t.Fatalf("recorded matrix:\n%s", audit.Matrix())
+	}
+	for i, mark := range []string{"+", "-", "+"} {
+		if !strings.HasSuffix(rows[i+1], "\t"+mark) || !strings.Contains(rows[i+1], "Info") {
+			t.Errorf("row %d is %q, expected the Info call marked %s", i+1, rows[i+1], mark)
+		}
+	}
+}
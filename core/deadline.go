@@ -0,0 +1,35 @@
+package core
+
+import (
+	"time"
+)
+
+// Helpers that copy or extract trees into the temp dir warn when less than
+// this much time is left before the test deadline.
+const NearDeadline = 30 * time.Second
+
+// Returns the test's deadline from the -timeout flag, if the underlying T
+// has one.
+func (x *BaseTest) Deadline() (time.Time, bool) {
+	d, ok := x.t.(interface{ Deadline() (time.Time, bool) })
+	if !ok {
+		return time.Time{}, false
+	}
+	return d.Deadline()
+}
+
+// Returns the time left before the test deadline, or the largest duration
+// when the test has none.
+func (x *BaseTest) RemainingTime() time.Duration {
+	deadline, ok := x.Deadline()
+	if !ok {
+		return time.Duration(1<<63 - 1)
+	}
+	return time.Until(deadline)
+}
+
+func (x *BaseTest) warnNearDeadline(helper, target string) {
+	if remaining := x.RemainingTime(); remaining < NearDeadline {
+		x.Logf("WARNING: %s('%s') started with only %s left before the test deadline", helper, target, remaining.Round(time.Millisecond))
+	}
+}
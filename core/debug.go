@@ -0,0 +1,49 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+)
+
+// Set GOONIT_DEBUG_ON_FAILURE to any non-empty value to have a failed test
+// pause before its cleanups run, so a debugger can be attached or live state
+// such as servers and temp files inspected.
+const DebugOnFailureEnv = "GOONIT_DEBUG_ON_FAILURE"
+
+// Records something a developer debugging a failed test would want to
+// know, such as a fixture server's address, to print when the test pauses.
+func (x *BaseTest) DebugInfo(name, value string) *BaseTest {
+	x.debugInfo = append(x.debugInfo, [2]string{name, value})
+	return x
+}
+
+// Pauses the failed test until it is signalled to resume, if
+// GOONIT_DEBUG_ON_FAILURE is set.  It is called when the test is Done, before
+// cleanups tear anything down, and again by the failure hooks for tests that
+// fail later; it only pauses once.
+func (x *BaseTest) pauseForDebugger() {
+	if x.debugPaused || os.Getenv(DebugOnFailureEnv) == "" || !x.t.Failed() {
+		return
+	}
+	x.debugPaused = true
+	resume := make(chan os.Signal, 1)
+	signal.Notify(resume, resumeSignals...)
+	defer signal.Stop(resume)
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "goonit: test %s failed and is paused for debugging\n", x.t.Name())
+	fmt.Fprintf(b, "  pid: %d\n", os.Getpid())
+	if x.tempDir != "" {
+		fmt.Fprintf(b, "  temp dir: %s\n", x.tempDir)
+	}
+	for _, info := range x.debugInfo {
+		fmt.Fprintf(b, "  %s: %s\n", info[0], info[1])
+	}
+	fmt.Fprintf(b, "  attach with: dlv attach %d\n", os.Getpid())
+	fmt.Fprintf(b, "  resume with: %s\n", resumeHint)
+	fmt.Fprintf(b, "the go test -timeout still applies; raise it to pause longer\n")
+	fmt.Fprint(os.Stderr, b.String())
+	<-resume
+	fmt.Fprintf(os.Stderr, "goonit: test %s resumed\n", x.t.Name())
+}
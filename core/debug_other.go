@@ -0,0 +1,12 @@
+//go:build windows || plan9 || js || wasip1
+// +build windows plan9 js wasip1
+
+package core
+
+import (
+	"os"
+)
+
+var resumeSignals = []os.Signal{os.Interrupt}
+
+const resumeHint = "Ctrl-C"
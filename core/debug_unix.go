@@ -0,0 +1,14 @@
+//go:build !windows && !plan9 && !js && !wasip1
+// +build !windows,!plan9,!js,!wasip1
+
+package core
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+var resumeSignals = []os.Signal{syscall.SIGUSR1}
+
+var resumeHint = fmt.Sprintf("kill -USR1 %d", os.Getpid())
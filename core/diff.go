@@ -0,0 +1,87 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sbernheim/goonit/diff"
+)
+
+// Sets how this test's golden file, equality and directory comparisons
+// show differences, overriding diff.SetDefault.
+func (x *BaseTest) SetDiff(d diff.Diff) *BaseTest {
+	x.differ = d
+	return x
+}
+
+// Returns the test's diff, the suite default, or fallback if neither is set.
+func (x *BaseTest) diffOr(fallback diff.Diff) diff.Diff {
+	if x.differ != nil {
+		return x.differ
+	}
+	return diff.Default(fallback)
+}
+
+// Returns the slash-separated paths of the regular files under dir.
+func (x *BaseTest) dirFiles(dir string) map[string]bool {
+	files := map[string]bool{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		files[filepath.ToSlash(rel)] = true
+		return err
+	})
+	if err != nil {
+		x.Fatalf("failed to list directory '%s': %s", dir, err.Error())
+	}
+	return files
+}
+
+// Fails the test unless both directories hold the same files with the same
+// content, showing which files only one has and how the others differ.
+func (x *BaseTest) ExpectDirsEqual(expectedDir, actualDir string) {
+	expected, actual := x.dirFiles(expectedDir), x.dirFiles(actualDir)
+	paths := []string{}
+	for path := range expected {
+		paths = append(paths, path)
+	}
+	for path := range actual {
+		if !expected[path] {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	d := x.diffOr(diff.Unified)
+	b := &strings.Builder{}
+	for _, path := range paths {
+		switch {
+		case !actual[path]:
+			fmt.Fprintf(b, "only in expected: %s\n", path)
+		case !expected[path]:
+			fmt.Fprintf(b, "only in actual: %s\n", path)
+		default:
+			e := x.readFile(filepath.Join(expectedDir, path))
+			a := x.readFile(filepath.Join(actualDir, path))
+			if fileDiff := d.Diff(e, a); fileDiff != "" {
+				fmt.Fprintf(b, "%s differs:\n%s\n", path, fileDiff)
+			}
+		}
+	}
+	if b.Len() > 0 {
+		x.Fatalf("directory '%s' does not match '%s':\n%s", actualDir, expectedDir, b.String())
+	}
+}
+
+func (x *BaseTest) readFile(path string) string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		x.Fatalf("failed to read file '%s': %s", path, err.Error())
+	}
+	return string(data)
+}
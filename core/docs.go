@@ -0,0 +1,81 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Set GOONIT_DOCS to a directory to have RunSuite write a Markdown summary
+// of each suite's declared scenarios and expectations there.
+const DocsEnv = "GOONIT_DOCS"
+
+// Declares the scenario the test covers, for the suite's documentation.
+func (x *BaseTest) Scenario(description string) *BaseTest {
+	x.scenario = description
+	return x
+}
+
+// Declares a behavior the test expects, for the suite's documentation.
+func (x *BaseTest) Expects(description string) *BaseTest {
+	x.expectations = append(x.expectations, description)
+	return x
+}
+
+type TestDoc struct {
+	Name         string
+	Scenario     string
+	Expectations []string
+	Metrics      string
+	Failed       bool
+}
+
+type SuiteDoc struct {
+	Suite string
+	Tests []TestDoc
+}
+
+func (x *BaseTest) testDoc(name string) TestDoc {
+	return TestDoc{
+		Name:         name,
+		Scenario:     x.scenario,
+		Expectations: append([]string{}, x.expectations...),
+		Metrics:      x.metrics.String(),
+		Failed:       x.t.Failed(),
+	}
+}
+
+// Returns the suite documentation as Markdown.
+func (d *SuiteDoc) Markdown() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "# %s\n", d.Suite)
+	for _, test := range d.Tests {
+		fmt.Fprintf(b, "\n## %s\n\n", test.Name)
+		if test.Scenario != "" {
+			fmt.Fprintf(b, "%s\n\n", test.Scenario)
+		}
+		for _, expectation := range test.Expectations {
+			fmt.Fprintf(b, "- %s\n", expectation)
+		}
+		if test.Metrics != "" {
+			fmt.Fprintf(b, "\n```\n%s\n```\n", test.Metrics)
+		}
+		if test.Failed {
+			b.WriteString("\n**Failing**\n")
+		}
+	}
+	return b.String()
+}
+
+func (d *SuiteDoc) write() error {
+	dir, ok := os.LookupEnv(DocsEnv)
+	if !ok || dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, d.Suite+".md"), []byte(d.Markdown()), 0666)
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Logs the env overrides, os.Args, captured values by mock call, the callers
+// that registered cleanups with DoAfter, metrics and the temp directory contents.
+func (x *BaseTest) DumpState() {
+	x.Logf("test state\n%s", x.state())
+}
+
+// Returns where the code outside goonit that called into it is.
+func (x *BaseTest) callerLogString() string {
+	if caller := x.GetCallerInfo(); caller != nil {
+		return caller.LogString()
+	}
+	return "unknown caller"
+}
+
+func (x *BaseTest) state() string {
+	b := &strings.Builder{}
+	b.WriteString("captured:")
+	capsFrom := x.allCapsFrom()
+	keys := x.capturedKeys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(b, "\n  %s: %v", key, capsFrom[key])
+	}
+	b.WriteString("\nenv:")
+	names := make([]string, 0, len(x.envs))
+	for name := range x.envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(b, "\n  %s=%s", name, x.envs[name])
+	}
+	fmt.Fprintf(b, "\nargs: %q", os.Args)
+	fmt.Fprintf(b, "\ncleanups registered by:")
+	for _, from := range x.afterFrom {
+		fmt.Fprintf(b, "\n  %s", from)
+	}
+	b.WriteString("\nmetrics:")
+	if metrics := x.metrics.String(); metrics != "" {
+		fmt.Fprintf(b, "\n  %s", strings.ReplaceAll(metrics, "\n", "\n  "))
+	}
+	fmt.Fprintf(b, "\ntemp dir: %s", x.tempDir)
+	for _, entry := range x.tempDirListing() {
+		fmt.Fprintf(b, "\n  %s", entry)
+	}
+	return b.String()
+}
+
+func (x *BaseTest) tempDirListing() []string {
+	listing := []string{}
+	if x.tempDir == "" {
+		return listing
+	}
+	filepath.Walk(x.tempDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == x.tempDir {
+			return nil
+		}
+		rel, _ := filepath.Rel(x.tempDir, path)
+		if info.IsDir() {
+			listing = append(listing, rel+"/")
+		} else {
+			listing = append(listing, fmt.Sprintf("%s (%d bytes, %s)", rel, info.Size(), info.Mode()))
+		}
+		return nil
+	})
+	return listing
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Passed to t.Setenv by SetArgs so the testing package rejects it in
+// parallel tests the same way it rejects SetEnv.
+const argsGuardEnv = "GOONIT_SET_ARGS"
+
+// Calls t.Setenv, which the testing package refuses in parallel tests and
+// which keeps the test from going parallel afterwards, with the variable's
+// current value, unsetting it again if it wasn't set, so the guard leaves
+// the environment as it was.
+func (x *BaseTest) guardProcessState(name string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("cannot change process state in parallel test %s; create the BaseTest WithIsolatedEnv() instead: %v", x.t.Name(), r)
+		}
+	}()
+	val, set := os.LookupEnv(name)
+	x.t.Setenv(name, val)
+	if !set {
+		os.Unsetenv(name)
+	}
+	return nil
+}
+
+// Returns the value of the environment variable, looking first at values
+// set with SetEnv, so code that reads it this way works WithIsolatedEnv.
+func (x *BaseTest) LookupEnv(name string) (string, bool) {
+	if val, found := x.envs[name]; found {
+		return val, true
+	}
+	return os.LookupEnv(name)
+}
+
+func (x *BaseTest) Getenv(name string) string {
+	val, _ := x.LookupEnv(name)
+	return val
+}
+
+// Returns the arguments set with SetArgs, or os.Args if there are none.
+func (x *BaseTest) Args() []string {
+	if x.args == nil {
+		return os.Args
+	}
+	return x.args
+}
+
+// Sets each variable in the map, in name order, until the test is Done.
+func (x *BaseTest) SetEnvMap(envs map[string]string) *BaseTest {
+	names := make([]string, 0, len(envs))
+	for name := range envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		x.SetEnv(name, envs[name])
+	}
+	return x
+}
+
+// Sets a variable for each field of the struct with an `env:"NAME"` tag,
+// formatting the field's value with fmt and
+// joining string slices with commas.  Skips untagged fields, fields
+// tagged `env:"-"` and nil pointers.
+func (x *BaseTest) SetEnvStruct(config interface{}) *BaseTest {
+	v := reflect.ValueOf(config)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		x.Fatalf("SetEnvStruct needs a struct or pointer to struct, passed %T", config)
+	}
+	envs := map[string]string{}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name, tagged := field.Tag.Lookup("env")
+		if !tagged || name == "" || name == "-" {
+			continue
+		}
+		if !field.IsExported() {
+			x.Fatalf("SetEnvStruct cannot read unexported field %s tagged env:%q", field.Name, name)
+		}
+		val := v.Field(i)
+		if val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				continue
+			}
+			val = val.Elem()
+		}
+		envs[name] = envValue(val.Interface())
+	}
+	return x.SetEnvMap(envs)
+}
+
+// Formats string slices comma-separated and everything else with fmt.
+func envValue(val interface{}) string {
+	if vals, ok := val.([]string); ok {
+		return strings.Join(vals, ",")
+	}
+	return fmt.Sprint(val)
+}
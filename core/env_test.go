@@ -0,0 +1,41 @@
+package core
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSetEnvRestoresValueBeforeTestOnDone(t *testing.T) {
+	t.Setenv("GOONIT_TEST_ENV", "before")
+	x := New(t)
+	x.SetEnv("GOONIT_TEST_ENV", "during")
+	if got := os.Getenv("GOONIT_TEST_ENV"); got != "during" {
+		t.Fatalf("during the test the variable is %q", got)
+	}
+	x.Done()
+	if got := os.Getenv("GOONIT_TEST_ENV"); got != "before" {
+		t.Fatalf("after Done the variable is %q, not the value from before the test", got)
+	}
+}
+
+func TestSetEnvUnsetsNewVariableOnDone(t *testing.T) {
+	os.Unsetenv("GOONIT_TEST_UNSET")
+	x := New(t)
+	x.SetEnv("GOONIT_TEST_UNSET", "during")
+	x.Done()
+	if val, set := os.LookupEnv("GOONIT_TEST_UNSET"); set {
+		t.Fatalf("after Done the variable is still set to %q", val)
+	}
+}
+
+func TestSetArgsLeavesEnvironmentAlone(t *testing.T) {
+	x := New(t)
+	defer x.Done()
+	x.SetArgs("cmd", "--flag")
+	if val, set := os.LookupEnv(argsGuardEnv); set {
+		t.Fatalf("SetArgs set %s=%q", argsGuardEnv, val)
+	}
+	if len(os.Args) != 2 || os.Args[1] != "--flag" {
+		t.Fatalf("os.Args is %v", os.Args)
+	}
+}
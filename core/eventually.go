@@ -0,0 +1,100 @@
+package core
+
+import (
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// Time left for reporting a failure after a capped Eventually or
+// Consistently times out, before the test binary's own deadline panics.
+const deadlineGrace = time.Second
+
+// Sets the timeout and polling interval Eventually and Consistently use when
+// they are called without explicit intervals.
+func (x *BaseTest) SetEventuallyDefaults(timeout, poll time.Duration) *BaseTest {
+	x.eventuallyTimeout = timeout
+	x.eventuallyPoll = poll
+	return x
+}
+
+// Wraps gomega's Eventually, using the defaults from SetEventuallyDefaults when
+// no intervals are given and capping the timeout at the test deadline.
+func (x *BaseTest) Eventually(actual interface{}, intervals ...interface{}) AsyncAssertion {
+	return x.WithT.Eventually(actual, x.asyncIntervals(intervals)...)
+}
+
+// Wraps gomega's Consistently, using the defaults from SetEventuallyDefaults
+// when no intervals are given and capping the duration at the test deadline.
+func (x *BaseTest) Consistently(actual interface{}, intervals ...interface{}) AsyncAssertion {
+	return x.WithT.Consistently(actual, x.asyncIntervals(intervals)...)
+}
+
+func (x *BaseTest) asyncIntervals(intervals []interface{}) []interface{} {
+	if len(intervals) == 0 && x.eventuallyTimeout > 0 {
+		intervals = []interface{}{x.eventuallyTimeout}
+		if x.eventuallyPoll > 0 {
+			intervals = append(intervals, x.eventuallyPoll)
+		}
+	}
+	return x.capAtDeadline(intervals)
+}
+
+func (x *BaseTest) capAtDeadline(intervals []interface{}) []interface{} {
+	if len(intervals) == 0 {
+		return intervals
+	}
+	if _, ok := x.Deadline(); !ok {
+		return intervals
+	}
+	timeout, ok := intervalDuration(intervals[0])
+	if !ok {
+		return intervals
+	}
+	remaining := x.RemainingTime() - deadlineGrace
+	if remaining <= 0 || timeout <= remaining {
+		return intervals
+	}
+	x.Logf("capping async timeout %s at the test deadline, %s away", timeout, remaining)
+	return append([]interface{}{remaining}, intervals[1:]...)
+}
+
+// Converts an interval in any form gomega accepts into a duration.
+func intervalDuration(interval interface{}) (time.Duration, bool) {
+	switch v := interval.(type) {
+	case time.Duration:
+		return v, true
+	case string:
+		d, err := time.ParseDuration(v)
+		return d, err == nil
+	case int:
+		return time.Duration(v) * time.Second, true
+	case int64:
+		return time.Duration(v) * time.Second, true
+	case float64:
+		return time.Duration(v * float64(time.Second)), true
+	}
+	return 0, false
+}
+
+// Polls the condition until it returns true, failing the test with the
+// description, the time waited and every goroutine's stack if it is still
+// false after the timeout, which is capped at the test deadline.  A poll
+// of zero or less polls every 10ms, as gomega's Eventually does by default.
+func (x *BaseTest) WaitFor(desc string, cond func() bool, timeout, poll time.Duration) {
+	if poll <= 0 {
+		poll = 10 * time.Millisecond
+	}
+	if capped, ok := intervalDuration(x.capAtDeadline([]interface{}{timeout})[0]); ok {
+		timeout = capped
+	}
+	start := time.Now()
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	for !cond() {
+		if time.Since(start) >= timeout {
+			x.Fatalf("timed out waiting for %s after %s\n\n%s", desc, time.Since(start).Round(time.Millisecond), allStacks())
+		}
+		<-ticker.C
+	}
+}
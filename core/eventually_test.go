@@ -0,0 +1,15 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWaitForDefaultsPollInterval(t *testing.T) {
+	x := New(t, WithoutMocks())
+	polls := 0
+	x.WaitFor("the third poll", func() bool {
+		polls++
+		return polls == 3
+	}, time.Second, 0)
+}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"os"
+	"os/exec"
+	"sort"
+)
+
+// Set in the environment of child processes to the test's temp directory.
+const TempDirEnv = "GOONIT_TEMP_DIR"
+
+// Host environment variables every child process needs to run at all.
+var baseChildEnv = []string{"PATH", "HOME", "USER", "TMPDIR", "TEMP", "TMP", "SYSTEMROOT"}
+
+// Passes the named variables from the test process's environment
+// to the child processes the test starts.
+func (x *BaseTest) Propagate(names ...string) *BaseTest {
+	x.propagated = append(x.propagated, names...)
+	return x
+}
+
+// Returns the environment for a child process: the basic host variables,
+// the propagated variables, every variable the test set with SetEnv,
+// GOONIT_TEMP_DIR and GOONIT_ARTIFACTS, in that order so later values win.
+func (x *BaseTest) ChildEnv() []string {
+	env := []string{}
+	for _, name := range append(append([]string{}, baseChildEnv...), x.propagated...) {
+		if val, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+val)
+		}
+	}
+	names := make([]string, 0, len(x.envs))
+	for name := range x.envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, name+"="+x.envs[name])
+	}
+	return append(env, TempDirEnv+"="+x.TempDir(), ArtifactsEnv+"="+x.artifactDir())
+}
+
+// Returns a command for the binary or script that runs with ChildEnv
+// in the temp directory.
+func (x *BaseTest) Command(name string, args ...string) *exec.Cmd {
+	cmd := exec.Command(name, args...)
+	cmd.Env = x.ChildEnv()
+	cmd.Dir = x.TempDir()
+	return cmd
+}
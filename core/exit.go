@@ -0,0 +1,101 @@
+package core
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Panicked by a trapped exit on the test goroutine and recovered by Done.
+type exitCalled struct{ code int }
+
+type exitTrap struct {
+	mu        sync.Mutex
+	goroutine string
+	exited    bool
+	code      int
+	expected  *int
+}
+
+// Returns an exit function to inject into the code under test in place of
+// os.Exit, and sets each of the exit variables, such as a package's
+// `var exit = os.Exit`, to it until the test is Done.  Calling it records the
+// code and stops the code under test: on the test goroutine it unwinds to the
+// deferred Done, elsewhere it ends the calling goroutine.
+func (x *BaseTest) TrapExit(exitVars ...*func(int)) func(int) {
+	trap := &exitTrap{goroutine: goroutineID()}
+	x.exitTrap = trap
+	exit := func(code int) {
+		trap.mu.Lock()
+		if !trap.exited {
+			trap.exited, trap.code = true, code
+		}
+		trap.mu.Unlock()
+		if goroutineID() == trap.goroutine {
+			panic(exitCalled{code})
+		}
+		runtime.Goexit()
+	}
+	for _, v := range exitVars {
+		v := v
+		original := *v
+		x.DoAfter(func() { *v = original })
+		*v = exit
+	}
+	x.DoAfter(x.verifyExit)
+	return exit
+}
+
+// Fails the test unless the trapped exit was called with the code.  Called
+// before the code under test exits, it is checked when the test is Done.
+func (x *BaseTest) ExpectExitCode(code int) {
+	trap := x.exitTrap
+	if trap == nil {
+		x.Fatalf("ExpectExitCode needs TrapExit to be called first")
+	}
+	trap.mu.Lock()
+	exited, actual := trap.exited, trap.code
+	if !exited {
+		trap.expected = &code
+	}
+	trap.mu.Unlock()
+	if exited && actual != code {
+		x.Fatalf("expected exit code %d but the code under test exited with %d", code, actual)
+	}
+}
+
+// Returns the code the trapped exit was called with, and whether it was.
+func (x *BaseTest) ExitCode() (int, bool) {
+	if x.exitTrap == nil {
+		return 0, false
+	}
+	x.exitTrap.mu.Lock()
+	defer x.exitTrap.mu.Unlock()
+	return x.exitTrap.code, x.exitTrap.exited
+}
+
+func (x *BaseTest) verifyExit() {
+	trap := x.exitTrap
+	trap.mu.Lock()
+	exited, actual, expected := trap.exited, trap.code, trap.expected
+	trap.mu.Unlock()
+	if expected == nil {
+		return
+	}
+	if !exited {
+		x.Fatalf("expected exit code %d but the code under test did not exit", *expected)
+	}
+	if actual != *expected {
+		x.Fatalf("expected exit code %d but the code under test exited with %d", *expected, actual)
+	}
+}
+
+func goroutineID() string {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	fields := strings.Fields(string(buf))
+	if len(fields) < 2 {
+		return ""
+	}
+	return fields[1]
+}
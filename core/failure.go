@@ -0,0 +1,24 @@
+package core
+
+// Registers a hook that runs when the test is Done, or during test cleanup,
+// only if the test failed, to log whatever state helps explain the failure.
+func (x *BaseTest) OnFailure(hook func()) *BaseTest {
+	x.failureHooks = append(x.failureHooks, hook)
+	return x
+}
+
+// Runs the failure hooks once, if the test failed.  Done runs it, and it is
+// registered as a cleanup both when the BaseTest is created and when its
+// temp directory is, so it runs before the temp directory is removed even
+// for tests that fail after Done.
+func (x *BaseTest) runFailureHooks() {
+	if x.failureHooksRan || !x.t.Failed() {
+		return
+	}
+	x.failureHooksRan = true
+	x.Logf("test failed; dumping test state\n%s", x.state())
+	for _, hook := range x.failureHooks {
+		hook()
+	}
+	x.pauseForDebugger()
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// lookalikeT is a testing.T lookalike that, like GinkgoT(), never runs
+// cleanups, and records failures and logs instead of reporting them.
+type lookalikeT struct {
+	*testing.T
+	failed bool
+	logs   []string
+}
+
+func (t *lookalikeT) Cleanup(func()) {}
+
+func (t *lookalikeT) Errorf(format string, args ...interface{}) {
+	t.failed = true
+	t.logs = append(t.logs, fmt.Sprintf(format, args...))
+}
+
+// lookalikeFatal is what lookalikeT.Fatalf panics with, in place of
+// stopping the test, for tests that recover it to check what failed.
+type lookalikeFatal struct{}
+
+func (t *lookalikeT) Fatalf(format string, args ...interface{}) {
+	t.Errorf(format, args...)
+	panic(lookalikeFatal{})
+}
+
+func (t *lookalikeT) Logf(format string, args ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprintf(format, args...))
+}
+
+func (t *lookalikeT) Failed() bool {
+	return t.failed
+}
+
+func (t *lookalikeT) logged(text string) bool {
+	for _, log := range t.logs {
+		if strings.Contains(log, text) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDoneRunsFailureHooksWithoutCleanups(t *testing.T) {
+	lt := &lookalikeT{T: t}
+	x := NewT(lt, WithoutMocks())
+	hooks := 0
+	x.OnFailure(func() { hooks++ })
+	lt.Errorf("something broke")
+	x.Done()
+	if hooks != 1 {
+		t.Fatalf("failure hook ran %d times", hooks)
+	}
+	if !lt.logged("dumping test state") {
+		t.Fatalf("Done did not dump the test state; logged %v", lt.logs)
+	}
+}
+
+func TestDoneSkipsFailureHooksForPassingTest(t *testing.T) {
+	lt := &lookalikeT{T: t}
+	x := NewT(lt, WithoutMocks())
+	x.OnFailure(func() { t.Fatal("failure hook ran for a passing test") })
+	x.Done()
+}
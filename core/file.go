@@ -0,0 +1,72 @@
+package core
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/gomega"
+)
+
+// FileAssert makes fluent assertions about a file the code under test wrote.
+type FileAssert struct {
+	x    *BaseTest
+	path string
+}
+
+// Returns a FileAssert for the file at path.
+func (x *BaseTest) ExpectFile(path string) *FileAssert {
+	return &FileAssert{x: x, path: path}
+}
+
+func (f *FileAssert) stat() os.FileInfo {
+	info, err := os.Stat(f.path)
+	f.x.Expect(err).ShouldNot(HaveOccurred(), "expected file '%s' to exist", f.path)
+	return info
+}
+
+func (f *FileAssert) content() string {
+	data, err := ioutil.ReadFile(f.path)
+	f.x.Expect(err).ShouldNot(HaveOccurred(), "failed to read file '%s'", f.path)
+	return string(data)
+}
+
+func (f *FileAssert) Exists() *FileAssert {
+	f.stat()
+	return f
+}
+
+func (f *FileAssert) NotExists() *FileAssert {
+	_, err := os.Stat(f.path)
+	f.x.Expect(os.IsNotExist(err)).Should(BeTrue(), "expected file '%s' not to exist", f.path)
+	return f
+}
+
+func (f *FileAssert) ContentEquals(expected string) *FileAssert {
+	f.x.Expect(f.content()).Should(Equal(expected), "content of file '%s'", f.path)
+	return f
+}
+
+func (f *FileAssert) ContentMatches(regex string) *FileAssert {
+	f.x.Expect(f.content()).Should(MatchRegexp(regex), "content of file '%s'", f.path)
+	return f
+}
+
+func (f *FileAssert) HasMode(mode os.FileMode) *FileAssert {
+	f.x.Expect(f.stat().Mode()).Should(Equal(mode), "mode of file '%s'", f.path)
+	return f
+}
+
+// Asserts the file holds JSON equivalent to expected, which may be
+// a JSON string, a []byte of JSON or any value that marshals to JSON.
+func (f *FileAssert) JSONEquals(expected interface{}) *FileAssert {
+	switch expected.(type) {
+	case string, []byte:
+	default:
+		data, err := json.Marshal(expected)
+		f.x.Expect(err).ShouldNot(HaveOccurred(), "failed to marshal expected JSON for file '%s'", f.path)
+		expected = data
+	}
+	f.x.Expect(f.content()).Should(MatchJSON(expected), "JSON content of file '%s'", f.path)
+	return f
+}
@@ -0,0 +1,33 @@
+package core
+
+import (
+	"reflect"
+	"runtime"
+	"time"
+)
+
+// Drops the reference held by the variable ref points to, forces garbage
+// collection cycles and fails the test unless finalized reports that the
+// object's finalizer or cleanup ran within the timeout.
+//
+// Pass the address of the only variable holding the object, e.g.
+//
+//	x.AssertFinalized(&conn, time.Second, func() bool { return atomic.LoadInt32(&closed) == 1 })
+func (x *BaseTest) AssertFinalized(ref interface{}, timeout time.Duration, finalized func() bool) {
+	v := reflect.ValueOf(ref)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		x.Fatalf("AssertFinalized needs a pointer to the variable holding the object, got %T", ref)
+	}
+	v.Elem().Set(reflect.Zero(v.Elem().Type()))
+	deadline := time.Now().Add(timeout)
+	for {
+		runtime.GC()
+		if finalized() {
+			return
+		}
+		if time.Now().After(deadline) {
+			x.Fatalf("object referenced by %T was not finalized within %s", ref, timeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,44 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Adds custom tags, checked in order before the platform tags,
+// when FixtureVariant resolves a fixture.
+func (x *BaseTest) SetFixtureTags(tags ...string) *BaseTest {
+	x.fixtureTags = append(x.fixtureTags, tags...)
+	return x
+}
+
+func (x *BaseTest) fixtureSuffixes() []string {
+	suffixes := append([]string{}, x.fixtureTags...)
+	return append(suffixes,
+		runtime.GOOS+"_"+runtime.GOARCH,
+		runtime.GOOS,
+		runtime.GOARCH)
+}
+
+// Returns the fixture variant that best matches the custom tags and the
+// current platform.  For a base of "testdata/config.yaml" on linux/amd64 it
+// checks config_<tag>.yaml for each custom tag, then config_linux_amd64.yaml,
+// config_linux.yaml, config_amd64.yaml and finally config.yaml itself.
+func (x *BaseTest) FixtureVariant(base string) string {
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	candidates := []string{}
+	for _, suffix := range x.fixtureSuffixes() {
+		candidates = append(candidates, stem+"_"+suffix+ext)
+	}
+	candidates = append(candidates, base)
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	x.Fatalf("no fixture variant found for '%s'; tried %v", base, candidates)
+	return ""
+}
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"testing"
+)
+
+// TypeCase is one instantiation of a generic test body, made with ForType.
+type TypeCase struct {
+	name string
+	run  func(x *BaseTest)
+}
+
+// Instantiates the generic body for T.  The body receives sample values of
+// T to test with, such as edge cases for the type.  Go cannot pass a generic
+// function uninstantiated, so name the type argument when passing the body:
+//
+//	core.ForTypes(t,
+//		core.ForType(testMax[int], math.MinInt, 0, math.MaxInt),
+//		core.ForType(testMax[string], "", "a", "b"),
+//		core.ForType(testMax[UserID], 1, 2),
+//	)
+func ForType[T any](body func(x *BaseTest, samples ...T), samples ...T) TypeCase {
+	return TypeCase{
+		name: typeOf[T]().String(),
+		run:  func(x *BaseTest) { body(x, samples...) },
+	}
+}
+
+// Runs each case as a subtest named for its type argument, with its own
+// BaseTest.
+func ForTypes(t *testing.T, cases ...TypeCase) {
+	t.Helper()
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			x := New(t)
+			defer x.Done()
+			c.run(x)
+		})
+	}
+}
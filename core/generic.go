@@ -0,0 +1,15 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/sbernheim/goonit/mock"
+)
+
+// Creates a BaseTest wired to the provider newP builds and returns both, so
+// tests for projects with an extended Provider can use its typed constructor
+// methods without type asserting x.Mock() in every test.
+func NewWith[P mock.Provider](t *testing.T, newP func(*testing.T) P, opts ...Option) (*BaseTest, P) {
+	p := newP(t)
+	return New(t, append(opts[:len(opts):len(opts)], WithProvider(p))...), p
+}
@@ -0,0 +1,19 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/sbernheim/goonit/mock"
+)
+
+func TestNewWithLeavesCallersOptionsAlone(t *testing.T) {
+	opts := make([]Option, 1, 2)
+	opts[0] = func(o *options) {}
+	x, p := NewWith(t, func(t *testing.T) mock.Provider { return mock.NewProvider(t) }, opts...)
+	if x.Mock() != p {
+		t.Errorf("test was not wired to the provider")
+	}
+	if opts[:2][1] != nil {
+		t.Errorf("NewWith wrote into the options' backing array")
+	}
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/sbernheim/goonit/diff"
+)
+
+// Set GOONIT_UPDATE_GOLDEN to any non-empty value to have ExpectGolden
+// rewrite golden files instead of comparing against them.
+const UpdateGoldenEnv = "GOONIT_UPDATE_GOLDEN"
+
+// Round trips the value through JSON so maps, structs and numbers all come out
+// as sorted maps, slices and json.Number literals.
+func canonicalValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var canonical interface{}
+	err = dec.Decode(&canonical)
+	return canonical, err
+}
+
+// Returns the value as indented JSON with sorted keys, numbers rendered as
+// encoding/json formats them, no HTML escaping and a trailing newline, so
+// regenerated golden files differ only where the value did.
+func CanonicalJSON(v interface{}) ([]byte, error) {
+	canonical, err := canonicalValue(v)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(canonical)
+	return buf.Bytes(), err
+}
+
+func yamlValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for key := range val {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		m := yaml.MapSlice{}
+		for _, key := range keys {
+			m = append(m, yaml.MapItem{Key: key, Value: yamlValue(val[key])})
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(val))
+		for i, item := range val {
+			s[i] = yamlValue(item)
+		}
+		return s
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		if f, err := val.Float64(); err == nil {
+			return f
+		}
+		return val.String()
+	default:
+		return val
+	}
+}
+
+// Returns the value as YAML with the same sorted keys and stable numbers as
+// CanonicalJSON, ending in a trailing newline.
+func CanonicalYAML(v interface{}) ([]byte, error) {
+	canonical, err := canonicalValue(v)
+	if err != nil {
+		return nil, err
+	}
+	data, err := yaml.Marshal(yamlValue(canonical))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasSuffix(data, []byte("\n")) {
+		data = append(data, '\n')
+	}
+	return data, nil
+}
+
+// Compares the canonical form of the value with the golden file, YAML for
+// .yaml and .yml files and JSON otherwise, or rewrites the golden file when
+// GOONIT_UPDATE_GOLDEN is set.
+func (x *BaseTest) ExpectGolden(goldenPath string, v interface{}) {
+	canonical := CanonicalJSON
+	if ext := strings.ToLower(filepath.Ext(goldenPath)); ext == ".yaml" || ext == ".yml" {
+		canonical = CanonicalYAML
+	}
+	actual, err := canonical(v)
+	if err != nil {
+		x.Fatalf("failed to serialize value for golden file '%s': %s", goldenPath, err.Error())
+	}
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0777); err != nil {
+			x.Fatalf("failed to create directory for golden file '%s': %s", goldenPath, err.Error())
+		}
+		if err := ioutil.WriteFile(goldenPath, actual, 0666); err != nil {
+			x.Fatalf("failed to write golden file '%s': %s", goldenPath, err.Error())
+		}
+		x.Logf("updated golden file '%s'", goldenPath)
+		return
+	}
+	expected, err := ioutil.ReadFile(goldenPath)
+	if err != nil {
+		x.Fatalf("failed to read golden file '%s' (set %s=1 to create it): %s", goldenPath, UpdateGoldenEnv, err.Error())
+	}
+	if d := x.diffOr(diff.Unified).Diff(string(expected), string(actual)); d != "" {
+		x.Fatalf("value does not match golden file '%s':\n%s", goldenPath, d)
+	}
+}
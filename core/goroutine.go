@@ -0,0 +1,109 @@
+package core
+
+import (
+	"strings"
+	"time"
+)
+
+// How long the goroutine check waits for goroutines to exit on their own
+// before reporting them as leaked.
+var GoroutineGrace = time.Second
+
+// Goroutines whose stacks contain any of these are never reported.  They
+// belong to the testing package, such as subtests, or to the runtime.
+var DefaultGoroutineIgnores = []string{
+	"testing.tRunner",
+	"testing.(*T).Run",
+	"testing.(*M).",
+	"os/signal.signal_recv",
+	"runtime.ensureSigM",
+}
+
+// GoroutineCheck reports goroutines started after it was created that are
+// still running when the test ends.
+type GoroutineCheck struct {
+	x        *BaseTest
+	before   map[string]bool
+	ignore   []string
+	verified bool
+}
+
+// Snapshots the running goroutines and, when the test is Done, after the
+// functions registered with DoAfter have shut servers and the like down,
+// fails the test with the stack of each new goroutine still running, other
+// than those whose stacks contain one of the ignore patterns.  Tests that
+// never call Done are checked when their cleanups run.
+func (x *BaseTest) CheckGoroutines(ignore ...string) *GoroutineCheck {
+	c := &GoroutineCheck{x: x, before: map[string]bool{}}
+	c.ignore = append(c.ignore, DefaultGoroutineIgnores...)
+	c.ignore = append(c.ignore, ignore...)
+	for id := range goroutines() {
+		c.before[id] = true
+	}
+	x.goroutineChecks = append(x.goroutineChecks, c)
+	x.t.Cleanup(c.verify)
+	return c
+}
+
+// Adds patterns for goroutines the check should not report, such as a
+// package's long-lived background worker.
+func (c *GoroutineCheck) Ignore(patterns ...string) *GoroutineCheck {
+	c.ignore = append(c.ignore, patterns...)
+	return c
+}
+
+// Returns the goroutines started since the check was created that are not
+// ignored, keyed by goroutine id.
+func (c *GoroutineCheck) Leaked() map[string]string {
+	leaked := map[string]string{}
+	for id, stack := range goroutines() {
+		if !c.before[id] && !c.ignored(stack) {
+			leaked[id] = stack
+		}
+	}
+	return leaked
+}
+
+func (c *GoroutineCheck) ignored(stack string) bool {
+	for _, pattern := range c.ignore {
+		if strings.Contains(stack, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *GoroutineCheck) verify() {
+	if c.verified {
+		return
+	}
+	c.verified = true
+	deadline := time.Now().Add(GoroutineGrace)
+	leaked := c.Leaked()
+	for len(leaked) > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		leaked = c.Leaked()
+	}
+	if len(leaked) == 0 {
+		return
+	}
+	b := &strings.Builder{}
+	for _, stack := range leaked {
+		b.WriteString("\n\n")
+		b.WriteString(stack)
+	}
+	c.x.t.Errorf("%s left %d goroutine(s) running:%s", c.x.t.Name(), len(leaked), b.String())
+}
+
+// Returns the stack of every goroutine, keyed by goroutine id.
+func goroutines() map[string]string {
+	stacks := map[string]string{}
+	for _, stack := range strings.Split(allStacks(), "\n\n") {
+		header := strings.Fields(stack)
+		if len(header) < 2 || header[0] != "goroutine" {
+			continue
+		}
+		stacks[header[1]] = stack
+	}
+	return stacks
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDoneChecksGoroutinesWithoutCleanups(t *testing.T) {
+	defer func(grace time.Duration) { GoroutineGrace = grace }(GoroutineGrace)
+	GoroutineGrace = 50 * time.Millisecond
+	lt := &lookalikeT{T: t}
+	x := NewT(lt, WithoutMocks())
+	x.CheckGoroutines()
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() { <-stop }()
+	x.Done()
+	if !lt.logged("left 1 goroutine(s) running") {
+		t.Fatalf("Done did not report the leaked goroutine; logged %v", lt.logs)
+	}
+}
+
+func TestDoneChecksGoroutinesAfterDoAfter(t *testing.T) {
+	x := New(t, WithoutMocks())
+	x.CheckGoroutines()
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		<-stop
+		close(stopped)
+	}()
+	x.DoAfter(func() {
+		close(stop)
+		<-stopped
+	})
+	x.Done()
+}
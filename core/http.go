@@ -0,0 +1,65 @@
+package core
+
+import (
+	"github.com/sbernheim/goonit/httpx"
+)
+
+// Starts an HTTP server replying from stubbed routes, shut down when the
+// test is Done.
+func (x *BaseTest) HTTPServer() *httpx.Server {
+	s := httpx.NewServer(x.t)
+	x.DebugInfo("HTTP server", s.URL)
+	x.DoAfter(s.Close)
+	return s
+}
+
+// Returns an http.Client, in its Client field, whose requests are answered
+// from stubbed routes without opening sockets.  Requests no route matches
+// fail the test.
+func (x *BaseTest) StubHTTPClient() *httpx.StubClient {
+	return httpx.NewStubClient(x.t)
+}
+
+// Returns a recorder that replays the HTTP interactions in the cassette
+// file, or records them from real services when GOONIT_HTTP_RECORD is set.
+// A recording is saved when the test is Done, unless the test failed.
+func (x *BaseTest) Cassette(path string) *httpx.Recorder {
+	r := httpx.NewRecorder(x.t, path, nil)
+	if r.Recording() {
+		x.DoAfter(func() {
+			if x.t.Failed() {
+				x.Logf("not saving cassette '%s' because the test failed", path)
+				return
+			}
+			if err := r.Save(); err != nil {
+				x.Fatalf("failed to save cassette '%s': %s", path, err.Error())
+			}
+			x.Logf("recorded cassette '%s'", path)
+		})
+	}
+	return r
+}
+
+// Starts a server streaming scripted server-sent events, shut down when
+// the test is Done.  Call UseClock(x.FakeClock()) on it to control delays.
+func (x *BaseTest) SSEServer() *httpx.SSEServer {
+	s := httpx.NewSSEServer(x.t)
+	x.DebugInfo("SSE server", s.URL)
+	x.DoAfter(s.Close)
+	return s
+}
+
+// Starts building a request to send straight to an http.Handler with Do,
+// which returns the response with assertions on it.
+func (x *BaseTest) Request(method, target string) *httpx.HandlerRequest {
+	return httpx.NewHandlerRequest(x.t, method, target)
+}
+
+// Starts a server replying to GraphQL operations by name and variables,
+// shut down when the test is Done.
+func (x *BaseTest) GraphQLServer() *httpx.GraphQLServer {
+	s := httpx.NewGraphQLServer(x.t)
+	x.DebugInfo("GraphQL server", s.URL)
+	x.DoAfter(s.Close)
+	return s
+}
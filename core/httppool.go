@@ -0,0 +1,155 @@
+package core
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+
+	. "github.com/onsi/gomega"
+)
+
+// TrackedTransport is an http.RoundTripper that records whether each request
+// reused a pooled connection and whether the code under test drained and
+// closed every response body, catching the classic leaked body bug.
+type TrackedTransport struct {
+	x      *BaseTest
+	base   http.RoundTripper
+	mu     sync.Mutex
+	newC   int
+	reused int
+	idle   int
+	bodies []*trackedBody
+}
+
+// Returns a TrackedTransport wrapping the base transport, or a clone of
+// http.DefaultTransport if base is nil.  When the test is Done it fails
+// the test if any response body was left open.
+func (x *BaseTest) TrackedTransport(base http.RoundTripper) *TrackedTransport {
+	if base == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		x.DoAfter(transport.CloseIdleConnections)
+		base = transport
+	}
+	tt := &TrackedTransport{x: x, base: base}
+	x.DoAfter(func() { tt.ExpectBodiesClosed() })
+	return tt
+}
+
+// Returns a client that sends requests through the transport.
+func (tt *TrackedTransport) Client() *http.Client {
+	return &http.Client{Transport: tt}
+}
+
+func (tt *TrackedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			tt.mu.Lock()
+			defer tt.mu.Unlock()
+			if info.Reused {
+				tt.reused++
+			} else {
+				tt.newC++
+			}
+			if info.WasIdle {
+				tt.idle++
+			}
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	resp, err := tt.base.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		return resp, err
+	}
+	body := &trackedBody{ReadCloser: resp.Body, url: req.URL.String()}
+	tt.mu.Lock()
+	tt.bodies = append(tt.bodies, body)
+	tt.mu.Unlock()
+	resp.Body = body
+	return resp, nil
+}
+
+// Returns how many requests opened new connections, reused pooled ones,
+// and reused ones that had been idle in the pool.
+func (tt *TrackedTransport) Conns() (newConns, reused, wasIdle int) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	return tt.newC, tt.reused, tt.idle
+}
+
+// Fails the test unless at least n requests reused a kept-alive connection.
+func (tt *TrackedTransport) ExpectReused(n int) *TrackedTransport {
+	_, reused, _ := tt.Conns()
+	tt.x.Expect(reused).Should(BeNumerically(">=", n), "requests reusing kept-alive connections")
+	return tt
+}
+
+// Fails the test if requests opened more than n new connections,
+// e.g. because the idle pool was too small or bodies weren't drained.
+func (tt *TrackedTransport) ExpectMaxNewConns(n int) *TrackedTransport {
+	newConns, _, _ := tt.Conns()
+	tt.x.Expect(newConns).Should(BeNumerically("<=", n), "requests opening new connections")
+	return tt
+}
+
+func (tt *TrackedTransport) unfinished(check func(b *trackedBody) bool) []string {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	urls := []string{}
+	for _, b := range tt.bodies {
+		if !check(b) {
+			urls = append(urls, b.url)
+		}
+	}
+	return urls
+}
+
+// Fails the test if any response body was not closed.
+func (tt *TrackedTransport) ExpectBodiesClosed() *TrackedTransport {
+	tt.x.Expect(tt.unfinished((*trackedBody).isClosed)).Should(BeEmpty(), "response bodies were not closed")
+	return tt
+}
+
+// Fails the test if any response body was not read to the end, which
+// keeps its connection from returning to the pool.
+func (tt *TrackedTransport) ExpectBodiesDrained() *TrackedTransport {
+	tt.x.Expect(tt.unfinished((*trackedBody).isDrained)).Should(BeEmpty(), "response bodies were not drained")
+	return tt
+}
+
+type trackedBody struct {
+	io.ReadCloser
+	url     string
+	mu      sync.Mutex
+	drained bool
+	closed  bool
+}
+
+func (b *trackedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.mu.Lock()
+		b.drained = true
+		b.mu.Unlock()
+	}
+	return n, err
+}
+
+func (b *trackedBody) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	return b.ReadCloser.Close()
+}
+
+func (b *trackedBody) isClosed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
+
+func (b *trackedBody) isDrained() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.drained
+}
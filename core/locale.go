@@ -0,0 +1,33 @@
+package core
+
+import (
+	"time"
+)
+
+// Sets TZ and time.Local to the named zone, such as "America/New_York", until
+// the test is Done, and returns the zone.  WithIsolatedEnv only the value
+// LookupEnv returns for TZ is set; pass the returned zone to the code under
+// test instead of relying on time.Local.
+func (x *BaseTest) SetTimezone(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		x.Fatalf("failed to load timezone '%s': %s", name, err.Error())
+	}
+	x.SetEnv("TZ", name)
+	if x.isolatedEnv {
+		return loc
+	}
+	local := time.Local
+	x.DoAfter(func() {
+		time.Local = local
+	})
+	time.Local = loc
+	return loc
+}
+
+// Sets LANG and LC_ALL to the locale, such as "de_DE" or "de_DE.UTF-8", until
+// the test is Done.  The Go standard library ignores locale; this is for
+// code under test, and commands it runs, that read them.
+func (x *BaseTest) SetLocale(locale string) *BaseTest {
+	return x.SetEnv("LANG", locale).SetEnv("LC_ALL", locale)
+}
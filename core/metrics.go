@@ -0,0 +1,82 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics are counters and timers about the test itself, such as fixtures
+// loaded or requests issued, reported in failure output and suite documentation.
+type Metrics struct {
+	mu       sync.Mutex
+	counters map[string]int64
+	timers   map[string]time.Duration
+}
+
+// Returns the test's metrics.
+func (x *BaseTest) Metrics() *Metrics {
+	if x.metrics == nil {
+		x.metrics = &Metrics{counters: map[string]int64{}, timers: map[string]time.Duration{}}
+	}
+	return x.metrics
+}
+
+func (m *Metrics) Inc(name string) {
+	m.Add(name, 1)
+}
+
+func (m *Metrics) Add(name string, n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counters[name] += n
+}
+
+// Adds the duration to the named timer.
+func (m *Metrics) Record(name string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.timers[name] += d
+}
+
+// Starts timing and returns a func that adds the elapsed time to the named timer.
+//
+//	defer x.Metrics().Time("load fixtures")()
+func (m *Metrics) Time(name string) func() {
+	start := time.Now()
+	return func() {
+		m.Record(name, time.Since(start))
+	}
+}
+
+func (m *Metrics) Counter(name string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counters[name]
+}
+
+func (m *Metrics) Timer(name string) time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.timers[name]
+}
+
+// Returns the metrics sorted by name, one per line.
+func (m *Metrics) String() string {
+	if m == nil {
+		return ""
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	lines := make([]string, 0, len(m.counters)+len(m.timers))
+	for name, n := range m.counters {
+		lines = append(lines, fmt.Sprintf("%s: %d", name, n))
+	}
+	for name, d := range m.timers {
+		lines = append(lines, fmt.Sprintf("%s: %s", name, d))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,248 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// migration is a version's up and down files in a golang-migrate layout,
+// such as 1_create_users.up.sql and 1_create_users.down.sql.
+type migration struct {
+	version uint64
+	up      string
+	down    string
+}
+
+var migrationFile = regexp.MustCompile(`^(\d+)_.*\.(up|down)\.sql$`)
+
+// migrated is a database and migrations directory Migrate applied.
+type migrated struct {
+	db  *sql.DB
+	dir string
+}
+
+// Migrate caches what it applied for the test binary, which is one per
+// package, so each database is only migrated once.  Holding the *sql.DB
+// keeps its address from being reused by a later database.
+var (
+	migrateMu sync.Mutex
+	migrateds []migrated
+)
+
+// Applies the migrations in the directory to the database, the first time
+// it is called for them in the package's tests; later calls return right
+// away.  Migrations are files in the golang-migrate layout, such as
+// "migrations/1_create_users.up.sql", applied in version order.  As with
+// golang-migrate the version is kept in a schema_migrations table, so
+// migrations already applied to a shared database by an earlier test
+// binary are skipped.  Each file is executed as a single Exec, so it may
+// hold several statements only if the driver allows it.
+//
+// For a database shared by the package's tests, migrate it back down with
+// TeardownMigrations where it is closed, such as in TestMain.  For a
+// database opened per test, use MigrateWithTeardown.
+func (x *BaseTest) Migrate(db *sql.DB, dir string) {
+	migrateMu.Lock()
+	defer migrateMu.Unlock()
+	for _, m := range migrateds {
+		if m.db == db && m.dir == dir {
+			return
+		}
+	}
+	if err := migrateUp(db, dir); err != nil {
+		x.Fatalf("failed to apply migrations in '%s': %s", dir, err.Error())
+	}
+	migrateds = append(migrateds, migrated{db: db, dir: dir})
+}
+
+// Applies the migrations in the directory to the database as Migrate does,
+// but every time, and migrates the database back down to a clean schema
+// when the test is Done.  The teardown runs ahead of the functions already
+// registered with DoAfter, so a database opened earlier in the test, such
+// as with sqlitedb.Open, is still open for it.
+func (x *BaseTest) MigrateWithTeardown(db *sql.DB, dir string) {
+	if err := migrateUp(db, dir); err != nil {
+		x.Fatalf("failed to apply migrations in '%s': %s", dir, err.Error())
+	}
+	x.doAfterFirst(func() {
+		if err := migrateDown(db, dir); err != nil {
+			x.t.Errorf("failed to tear down migrations in '%s': %s", dir, err.Error())
+		}
+	})
+}
+
+// Migrates the database Migrate migrated with the directory back down to a
+// clean schema, for the code that owns a shared database to call before
+// closing it:
+//
+//	func TestMain(m *testing.M) {
+//		db = openTestDB()
+//		code := m.Run()
+//		if err := core.TeardownMigrations(db, "migrations"); err != nil {
+//			fmt.Fprintln(os.Stderr, err)
+//			code = 1
+//		}
+//		db.Close()
+//		os.Exit(code)
+//	}
+//
+// Migrate applies the migrations again if it is called for them later.
+func TeardownMigrations(db *sql.DB, dir string) error {
+	migrateMu.Lock()
+	defer migrateMu.Unlock()
+	for i, m := range migrateds {
+		if m.db == db && m.dir == dir {
+			migrateds = append(migrateds[:i], migrateds[i+1:]...)
+			break
+		}
+	}
+	if err := migrateDown(db, dir); err != nil {
+		return fmt.Errorf("failed to tear down migrations in '%s': %s", dir, err.Error())
+	}
+	return nil
+}
+
+// Returns the migrations in the directory in version order.
+func readMigrations(dir string) ([]migration, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[uint64]*migration{}
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad version in migration file '%s': %s", entry.Name(), err.Error())
+		}
+		m, found := byVersion[version]
+		if !found {
+			m = &migration{version: version}
+			byVersion[version] = m
+		}
+		path := filepath.Join(dir, entry.Name())
+		if match[2] == "up" {
+			if m.up != "" {
+				return nil, fmt.Errorf("migration files '%s' and '%s' have the same version", m.up, path)
+			}
+			m.up = path
+		} else {
+			if m.down != "" {
+				return nil, fmt.Errorf("migration files '%s' and '%s' have the same version", m.down, path)
+			}
+			m.down = path
+		}
+	}
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	return migrations, nil
+}
+
+// Returns the version recorded in schema_migrations, creating the table if
+// need be, and 0 if there is none.  Fails if a migration left it dirty.
+func migrationVersion(db *sql.DB) (uint64, error) {
+	_, err := db.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)")
+	if err != nil {
+		return 0, err
+	}
+	var version uint64
+	var dirty bool
+	err = db.QueryRow("SELECT version, dirty FROM schema_migrations").Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("database is dirty at version %d; a migration failed part way and the schema needs fixing by hand", version)
+	}
+	return version, nil
+}
+
+// Records the version, or that there is none if it is 0.
+func setMigrationVersion(db *sql.DB, version uint64, dirty bool) error {
+	if _, err := db.Exec("DELETE FROM schema_migrations"); err != nil {
+		return err
+	}
+	if version == 0 && !dirty {
+		return nil
+	}
+	_, err := db.Exec(fmt.Sprintf("INSERT INTO schema_migrations (version, dirty) VALUES (%d, %t)", version, dirty))
+	return err
+}
+
+func migrateUp(db *sql.DB, dir string) error {
+	migrations, err := readMigrations(dir)
+	if err != nil {
+		return err
+	}
+	current, err := migrationVersion(db)
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if m.version <= current || m.up == "" {
+			continue
+		}
+		if err := runMigration(db, m.up, m.version, m.version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func migrateDown(db *sql.DB, dir string) error {
+	migrations, err := readMigrations(dir)
+	if err != nil {
+		return err
+	}
+	current, err := migrationVersion(db)
+	if err != nil {
+		return err
+	}
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.version > current {
+			continue
+		}
+		if m.down == "" {
+			return fmt.Errorf("migration version %d has no down file", m.version)
+		}
+		var previous uint64
+		if i > 0 {
+			previous = migrations[i-1].version
+		}
+		if err := runMigration(db, m.down, m.version, previous); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Executes the migration file, marking the database dirty at the version
+// while it runs and recording the version it ends at once it succeeds.
+func runMigration(db *sql.DB, path string, version, to uint64) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := setMigrationVersion(db, version, true); err != nil {
+		return err
+	}
+	if _, err := db.Exec(string(data)); err != nil {
+		return fmt.Errorf("failed to execute migration file '%s': %s", path, err.Error())
+	}
+	return setMigrationVersion(db, to, false)
+}
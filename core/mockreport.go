@@ -0,0 +1,46 @@
+package core
+
+import (
+	"fmt"
+)
+
+// mockReporter reports failures from the mock controller in the test,
+// adding where the mock was called from and by which test.
+type mockReporter struct {
+	x *BaseTest
+}
+
+func (r *mockReporter) Helper() {
+	r.x.t.Helper()
+}
+
+func (r *mockReporter) Errorf(format string, args ...interface{}) {
+	r.x.t.Helper()
+	r.x.t.Errorf("%s%s", fmt.Sprintf(format, args...), r.where())
+}
+
+func (r *mockReporter) Fatalf(format string, args ...interface{}) {
+	r.x.t.Helper()
+	r.x.t.Fatalf("%s%s", fmt.Sprintf(format, args...), r.where())
+}
+
+// Lets the controller register its Finish as a cleanup, as it does with a
+// *testing.T.
+func (r *mockReporter) Cleanup(f func()) {
+	r.x.t.Cleanup(f)
+}
+
+func (r *mockReporter) where() string {
+	s := r.x.BuildCallerStack()
+	where := ""
+	if s.Mocked != nil {
+		where += fmt.Sprintf("\n  mock %s", s.Mocked.LogString())
+		if s.Mocker != nil {
+			where += fmt.Sprintf("\n  called by %s", s.Mocker.LogString())
+		}
+	}
+	if s.Test != nil {
+		where += fmt.Sprintf("\n  in test %s", s.Test.LogString())
+	}
+	return where
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"github.com/go-logr/logr"
+
+	"github.com/sbernheim/goonit/mock"
+)
+
+// Option configures a BaseTest created by New.
+type Option func(*options)
+
+type options struct {
+	withoutMocks bool
+	provider     mock.Provider
+	logger       logr.Logger
+	tempDir      string
+	isolatedEnv  bool
+	uploaders    []Uploader
+}
+
+// Skips creating a gomock controller and mock logger for tests that need neither.
+// Mock() and MockLogr() return nil.
+func WithoutMocks() Option {
+	return func(o *options) {
+		o.withoutMocks = true
+	}
+}
+
+// Uses the provider in place of a new mock.BaseProvider.
+func WithProvider(p mock.Provider) Option {
+	return func(o *options) {
+		o.provider = p
+	}
+}
+
+// Sets the logger Logger() returns in place of the test logger.
+func WithLogger(l logr.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// Uses the existing directory as the temp directory in place of t.TempDir().
+// The directory is not removed after the test.
+func WithTempDir(path string) Option {
+	return func(o *options) {
+		o.tempDir = path
+	}
+}
+
+// Keeps SetEnv and SetArgs values inside the BaseTest instead of changing the
+// process environment and os.Args, so parallel tests can set them safely.
+// Code under test must read them through Getenv, LookupEnv and Args,
+// and child processes get them through ChildEnv.
+func WithIsolatedEnv() Option {
+	return func(o *options) {
+		o.isolatedEnv = true
+	}
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/golang/mock/gomock"
+)
+
+// Expects the calls to happen in the order given, like gomock.InOrder, but
+// reports a call out of order with the order the calls were observed in.
+func (x *BaseTest) InOrder(calls ...*gomock.Call) *BaseTest {
+	seq := &callSequence{x: x, expected: calls}
+	for i, call := range calls {
+		i, methodType := i, x.mockedMethodType(call)
+		name := x.mockedCallName(call)
+		call.Do(reflect.MakeFunc(methodType, func(args []reflect.Value) []reflect.Value {
+			seq.observe(i, fmt.Sprintf("%s%v", name, argValues(methodType, args)))
+			return zeroResults(methodType)
+		}).Interface())
+	}
+	return x
+}
+
+func (x *BaseTest) mockedMethodType(call *gomock.Call) reflect.Type {
+	methodType, _, _, _ := x.mockedMethod(call)
+	return methodType
+}
+
+func (x *BaseTest) mockedCallName(call *gomock.Call) string {
+	_, _, mock, method := x.mockedMethod(call)
+	return mock + "." + method
+}
+
+func zeroResults(methodType reflect.Type) []reflect.Value {
+	rets := make([]reflect.Value, methodType.NumOut())
+	for i := range rets {
+		rets[i] = reflect.Zero(methodType.Out(i))
+	}
+	return rets
+}
+
+type callSequence struct {
+	x        *BaseTest
+	expected []*gomock.Call
+	mu       sync.Mutex
+	latest   int
+	observed []string
+}
+
+func (s *callSequence) observe(index int, call string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observed = append(s.observed, call)
+	if index < s.latest {
+		expected := make([]string, len(s.expected))
+		for i, c := range s.expected {
+			expected[i] = fmt.Sprintf("  %d. %s", i+1, c)
+		}
+		s.x.t.Errorf("call %s happened after a later call in order\nexpected order:\n%s\nobserved order:\n  %s",
+			call, strings.Join(expected, "\n"), strings.Join(s.observed, "\n  "))
+		return
+	}
+	s.latest = index
+}
+
+// Fails the test unless calls with the names, such as "MockRepo.Get", were
+// recorded in that order, though other calls may come between them.  It
+// checks the calls recorded for Verify.
+func (x *BaseTest) ExpectCallOrder(names ...string) *BaseTest {
+	calls := x.MockCalls()
+	next := 0
+	for _, call := range calls {
+		if next < len(names) && call.Mock+"."+call.Method == names[next] {
+			next++
+		}
+	}
+	if next < len(names) {
+		observed := make([]string, len(calls))
+		for i, call := range calls {
+			observed[i] = "  " + call.String()
+		}
+		if len(observed) == 0 {
+			observed = []string{"  no calls were recorded"}
+		}
+		after := ""
+		if next > 0 {
+			after = " after " + names[next-1]
+		}
+		x.Fatalf("expected calls in order %s but %s was not recorded%s\nobserved order:\n%s",
+			strings.Join(names, ", "), names[next], after, strings.Join(observed, "\n"))
+	}
+	return x
+}
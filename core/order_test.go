@@ -0,0 +1,28 @@
+package core
+
+import "testing"
+
+func TestInOrderReportsCallOutOfOrder(t *testing.T) {
+	lt := &lookalikeT{T: t}
+	x := NewT(lt)
+	l := x.MockLogr()
+	x.InOrder(l.EXPECT().Info("first"), l.EXPECT().Info("second"))
+	l.Info("second")
+	l.Info("first")
+	x.Done()
+	if !lt.logged("call MockLogger.Info[first] happened after a later call in order") {
+		t.Fatalf("out of order call not reported; logged %v", lt.logs)
+	}
+	if !lt.logged("observed order:\n  MockLogger.Info[second]\n  MockLogger.Info[first]") {
+		t.Errorf("observed order not reported; logged %v", lt.logs)
+	}
+}
+
+func TestInOrderAcceptsCallsInOrder(t *testing.T) {
+	x := New(t)
+	l := x.MockLogr()
+	x.InOrder(l.EXPECT().Info("first"), l.EXPECT().Info("second"))
+	l.Info("first")
+	l.Info("second")
+	x.Done()
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"net"
+	"time"
+)
+
+// ConnPair is a pair of connected in-memory net.Conns, one for the code under
+// test and one for the test to play the peer.
+type ConnPair struct {
+	x      *BaseTest
+	Client net.Conn
+	Server net.Conn
+}
+
+// Returns a connected pair of synchronous in-memory net.Conns from net.Pipe
+// that are closed when the test is Done.
+func (x *BaseTest) Pipe() *ConnPair {
+	client, server := net.Pipe()
+	x.DoAfter(func() {
+		client.Close()
+		server.Close()
+	})
+	return &ConnPair{x: x, Client: client, Server: server}
+}
+
+// Sets read and write deadlines the duration from now on both ends,
+// so a handler that blocks fails the test instead of hanging it.
+func (p *ConnPair) Deadline(d time.Duration) *ConnPair {
+	deadline := time.Now().Add(d)
+	for _, c := range []net.Conn{p.Client, p.Server} {
+		if err := c.SetDeadline(deadline); err != nil {
+			p.x.Fatalf("failed to set pipe deadline: %s", err.Error())
+		}
+	}
+	return p
+}
+
+// Writes the data to the conn and fails the test if it cannot within the duration.
+func (p *ConnPair) WriteWithin(c net.Conn, data []byte, d time.Duration) {
+	c.SetWriteDeadline(time.Now().Add(d))
+	defer c.SetWriteDeadline(time.Time{})
+	if _, err := c.Write(data); err != nil {
+		p.x.Fatalf("failed to write %d bytes to pipe within %s: %s", len(data), d, err.Error())
+	}
+}
+
+// Reads n bytes from the conn and fails the test if they don't arrive within the duration.
+func (p *ConnPair) ReadWithin(c net.Conn, n int, d time.Duration) []byte {
+	c.SetReadDeadline(time.Now().Add(d))
+	defer c.SetReadDeadline(time.Time{})
+	buf := make([]byte, n)
+	read := 0
+	for read < n {
+		m, err := c.Read(buf[read:])
+		read += m
+		if err != nil {
+			p.x.Fatalf("read %d of %d bytes from pipe within %s: %s", read, n, d, err.Error())
+		}
+	}
+	return buf
+}
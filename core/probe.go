@@ -0,0 +1,145 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// probeT is a T that records failures instead of reporting them, so goonit
+// can run test code repeatedly, e.g. while shrinking a property counterexample
+// or retrying a flaky body, and report only the outcome it decides on.
+type probeT struct {
+	name     string
+	failed   bool
+	skipped  bool
+	logs     []string
+	cleanups []func()
+}
+
+func newProbeT(name string) *probeT {
+	return &probeT{name: name}
+}
+
+// Runs the body on its own goroutine, so FailNow can stop it with
+// runtime.Goexit like the testing package does, then runs the cleanups.
+// Panics in the body are recorded as failures.
+func (p *probeT) run(body func(t *probeT)) *probeT {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			if r := recover(); r != nil {
+				p.failed = true
+				p.logs = append(p.logs, fmt.Sprintf("panic: %v", r))
+			}
+		}()
+		body(p)
+	}()
+	<-done
+	for i := len(p.cleanups) - 1; i >= 0; i-- {
+		p.cleanups[i]()
+	}
+	return p
+}
+
+func (p *probeT) output() string {
+	return strings.Join(p.logs, "\n")
+}
+
+func (p *probeT) Cleanup(f func()) {
+	p.cleanups = append(p.cleanups, f)
+}
+
+func (p *probeT) Setenv(key, value string) {
+	prev, ok := os.LookupEnv(key)
+	os.Setenv(key, value)
+	p.Cleanup(func() {
+		if ok {
+			os.Setenv(key, prev)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+func (p *probeT) Error(args ...interface{}) {
+	p.Log(args...)
+	p.Fail()
+}
+
+func (p *probeT) Errorf(format string, args ...interface{}) {
+	p.Logf(format, args...)
+	p.Fail()
+}
+
+func (p *probeT) Fail() {
+	p.failed = true
+}
+
+func (p *probeT) FailNow() {
+	p.Fail()
+	runtime.Goexit()
+}
+
+func (p *probeT) Failed() bool {
+	return p.failed
+}
+
+func (p *probeT) Fatal(args ...interface{}) {
+	p.Log(args...)
+	p.FailNow()
+}
+
+func (p *probeT) Fatalf(format string, args ...interface{}) {
+	p.Logf(format, args...)
+	p.FailNow()
+}
+
+func (p *probeT) Helper() {
+}
+
+func (p *probeT) Log(args ...interface{}) {
+	p.logs = append(p.logs, fmt.Sprintln(args...))
+}
+
+func (p *probeT) Logf(format string, args ...interface{}) {
+	p.logs = append(p.logs, fmt.Sprintf(format, args...))
+}
+
+func (p *probeT) Name() string {
+	return p.name
+}
+
+func (p *probeT) Parallel() {
+}
+
+func (p *probeT) Skip(args ...interface{}) {
+	p.Log(args...)
+	p.SkipNow()
+}
+
+func (p *probeT) SkipNow() {
+	p.skipped = true
+	runtime.Goexit()
+}
+
+func (p *probeT) Skipf(format string, args ...interface{}) {
+	p.Logf(format, args...)
+	p.SkipNow()
+}
+
+func (p *probeT) Skipped() bool {
+	return p.skipped
+}
+
+func (p *probeT) TempDir() string {
+	dir, err := ioutil.TempDir("", "goonit")
+	if err != nil {
+		p.Fatalf("failed to create temp directory: %s", err.Error())
+	}
+	p.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing/quick"
+)
+
+// Generator generates random values for property tests and proposes
+// smaller variations of a failing value to shrink it to a minimal counterexample.
+type Generator interface {
+	Generate(r *rand.Rand) interface{}
+	Shrink(value interface{}) []interface{}
+}
+
+// How many values ForAll checks.
+var PropertyRuns = 100
+
+type intGen struct{ min, max int }
+
+// Generates ints from min up to and including max, shrinking toward
+// the value in range closest to zero.  Panics if max is less than min.
+func Ints(min, max int) Generator {
+	if max < min {
+		panic(fmt.Sprintf("core.Ints max %d is less than min %d", max, min))
+	}
+	return &intGen{min: min, max: max}
+}
+
+func (g *intGen) Generate(r *rand.Rand) interface{} {
+	return intBetween(r, g.min, g.max)
+}
+
+func (g *intGen) target() int {
+	switch {
+	case g.min > 0:
+		return g.min
+	case g.max < 0:
+		return g.max
+	}
+	return 0
+}
+
+func (g *intGen) Shrink(value interface{}) []interface{} {
+	v, target := value.(int), g.target()
+	shrinks := []interface{}{}
+	for d := v - target; d != 0; d /= 2 {
+		shrinks = append(shrinks, v-d)
+	}
+	return shrinks
+}
+
+type stringGen struct{ maxLen int }
+
+// Generates strings of letters and digits up to maxLen long,
+// shrinking by dropping characters.
+func Strings(maxLen int) Generator {
+	return &stringGen{maxLen: maxLen}
+}
+
+func (g *stringGen) Generate(r *rand.Rand) interface{} {
+	b := make([]byte, r.Intn(g.maxLen+1))
+	for i := range b {
+		b[i] = randomChars[r.Intn(len(randomChars))]
+	}
+	return string(b)
+}
+
+func (g *stringGen) Shrink(value interface{}) []interface{} {
+	s := value.(string)
+	shrinks := []interface{}{}
+	if len(s) > 0 {
+		shrinks = append(shrinks, "")
+	}
+	if len(s) > 1 {
+		shrinks = append(shrinks, s[:len(s)/2], s[len(s)/2:])
+	}
+	for i := 0; i < len(s) && i < 16; i++ {
+		shrinks = append(shrinks, s[:i]+s[i+1:])
+	}
+	return shrinks
+}
+
+type quickGen struct{ t reflect.Type }
+
+// Generates arbitrary values of the example's type with testing/quick.
+// These values are not shrunk.
+func ValuesOf(example interface{}) Generator {
+	return &quickGen{t: reflect.TypeOf(example)}
+}
+
+func (g *quickGen) Generate(r *rand.Rand) interface{} {
+	v, ok := quick.Value(g.t, r)
+	if !ok {
+		panic("testing/quick cannot generate values of type " + g.t.String())
+	}
+	return v.Interface()
+}
+
+func (g *quickGen) Shrink(value interface{}) []interface{} {
+	return nil
+}
+
+// Returns the failure output if the property fails for the value, each run
+// getting a fresh BaseTest so mocks and captures don't bleed between values.
+func (x *BaseTest) checkProperty(property func(x *BaseTest, value interface{}), value interface{}) (string, bool) {
+	p := newProbeT(x.t.Name()).run(func(p *probeT) {
+		px := NewT(p)
+		defer px.Done()
+		property(px, value)
+	})
+	return p.output(), !p.failed
+}
+
+// Checks the property against PropertyRuns values from the generator using
+// the test's seeded Rand.  When a value fails, it is shrunk to the smallest
+// failing value the generator proposes, which is then reported with the
+// property's failure output and the seed that reproduces it.
+func (x *BaseTest) ForAll(gen Generator, property func(x *BaseTest, value interface{})) {
+	r := x.Rand()
+	for run := 1; run <= PropertyRuns; run++ {
+		value := gen.Generate(r)
+		output, ok := x.checkProperty(property, value)
+		if ok {
+			continue
+		}
+		original, shrinks := value, 0
+		for shrunk := true; shrunk; {
+			shrunk = false
+			for _, candidate := range gen.Shrink(value) {
+				if out, ok := x.checkProperty(property, candidate); !ok {
+					value, output, shrunk = candidate, out, true
+					shrinks++
+					break
+				}
+			}
+		}
+		x.Fatalf("property failed on run %d for %#v (shrunk %d times from %#v)\n%s", run, value, shrinks, original, output)
+	}
+}
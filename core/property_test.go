@@ -0,0 +1,27 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestIntsCoversWideRanges(t *testing.T) {
+	x := New(t, WithoutMocks())
+	x.ForAll(Ints(0, math.MaxInt), func(x *BaseTest, value interface{}) {
+		if value.(int) < 0 {
+			x.Fatalf("generated %d", value)
+		}
+	})
+	x.ForAll(Ints(math.MinInt, math.MaxInt), func(x *BaseTest, value interface{}) {})
+}
+
+func TestIntsRejectsEmptyRange(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "max 1 is less than min 5") {
+			t.Errorf("recovered %v, expected a panic about the range", r)
+		}
+	}()
+	Ints(5, 1)
+}
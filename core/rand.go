@@ -0,0 +1,124 @@
+package core
+
+import (
+	"flag"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sbernheim/goonit/fake"
+)
+
+// Set GOONIT_SEED, or pass -goonit.seed to the test binary, to replay the
+// random values a failing run used.
+const SeedEnv = "GOONIT_SEED"
+
+var seedFlag = flag.Int64("goonit.seed", 0, "seed for goonit random values; 0 picks a new seed each run")
+
+// Chosen once per run so every test's seed changes between runs unless
+// it is overridden.
+var runSeed = time.Now().UnixNano()
+
+// Returns the seed from -goonit.seed or GOONIT_SEED, if either is set.
+func seedOverride() (int64, bool) {
+	if *seedFlag != 0 {
+		return *seedFlag, true
+	}
+	if seed, err := strconv.ParseInt(os.Getenv(SeedEnv), 10, 64); err == nil {
+		return seed, true
+	}
+	return 0, false
+}
+
+// Returns the seed from -goonit.seed or GOONIT_SEED, or the run's seed
+// if neither is set.
+func configuredSeed() int64 {
+	if seed, found := seedOverride(); found {
+		return seed
+	}
+	return runSeed
+}
+
+// Returns the seed behind all of the test's random values, derived from the
+// run's seed and the test name so each test draws different values, or
+// taken as is from -goonit.seed or GOONIT_SEED.  It is logged if the test
+// fails, so rerunning just that test with GOONIT_SEED replays its values.
+func (x *BaseTest) Seed() int64 {
+	if !x.seeded {
+		x.seeded = true
+		x.seed = configuredSeed()
+		if _, found := seedOverride(); !found {
+			h := fnv.New64a()
+			h.Write([]byte(x.t.Name()))
+			x.seed ^= int64(h.Sum64())
+		}
+		seed := x.seed
+		x.OnFailure(func() {
+			x.Logf("random values used %s=%d; rerun with -run '^%s$' and %s=%d to replay them", SeedEnv, seed, x.t.Name(), SeedEnv, seed)
+		})
+	}
+	return x.seed
+}
+
+// Returns the test's random source, seeded with Seed.
+func (x *BaseTest) Rand() *rand.Rand {
+	if x.rand == nil {
+		x.rand = rand.New(rand.NewSource(x.Seed()))
+	}
+	return x.rand
+}
+
+const randomChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// Returns a random string of n letters and digits.
+func (x *BaseTest) RandomString(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = randomChars[x.Rand().Intn(len(randomChars))]
+	}
+	return string(b)
+}
+
+// Returns a random int from min up to and including max.
+func (x *BaseTest) RandomInt(min, max int) int {
+	if max < min {
+		x.Fatalf("RandomInt max %d is less than min %d", max, min)
+	}
+	return intBetween(x.Rand(), min, max)
+}
+
+// Returns a random int from min up to and including max, which must not be
+// less than min, even when the range is too wide for max-min+1 to fit an
+// int.  Ranges that fit draw the same values Intn does.
+func intBetween(r *rand.Rand, min, max int) int {
+	span := uint64(max) - uint64(min)
+	if span < math.MaxInt {
+		return min + r.Intn(int(span)+1)
+	}
+	if span == math.MaxUint64 {
+		return int(r.Uint64())
+	}
+	for {
+		if v := r.Uint64(); v <= span {
+			return int(uint64(min) + v)
+		}
+	}
+}
+
+func (x *BaseTest) RandomBytes(n int) []byte {
+	b := make([]byte, n)
+	x.Rand().Read(b)
+	return b
+}
+
+// Returns a fake data generator drawing from the test's random source,
+// so fake values can be replayed with the same seed.
+func (x *BaseTest) Fake() *fake.Faker {
+	if x.fake == nil {
+		x.fake = fake.NewFromRand(x.Rand())
+	}
+	return x.fake
+}
@@ -0,0 +1,17 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRandomIntCoversWideRanges(t *testing.T) {
+	x := New(t, WithoutMocks())
+	for _, r := range [][2]int{{0, math.MaxInt}, {math.MinInt, math.MaxInt}, {math.MinInt, 0}, {-1, math.MaxInt}, {7, 7}} {
+		for i := 0; i < 100; i++ {
+			if v := x.RandomInt(r[0], r[1]); v < r[0] || v > r[1] {
+				t.Fatalf("RandomInt(%d, %d) returned %d", r[0], r[1], v)
+			}
+		}
+	}
+}
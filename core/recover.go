@@ -0,0 +1,86 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/gomega"
+)
+
+// RecoveryAssert makes assertions about how a recovery boundary handled a panic.
+type RecoveryAssert struct {
+	x          *BaseTest
+	panicValue interface{}
+}
+
+// Runs the boundary under test, such as a worker loop or gRPC interceptor that
+// calls a dependency set up to panic with panicValue, and fails the test
+// if the panic escapes the boundary instead of being recovered.
+func (x *BaseTest) ExpectRecovered(boundary func(), panicValue interface{}) *RecoveryAssert {
+	escaped, value := x.runRecovered(boundary)
+	if escaped {
+		x.Fatalf("panic escaped the recovery boundary: expected %v to be recovered but got %v", panicValue, value)
+	}
+	return &RecoveryAssert{x: x, panicValue: panicValue}
+}
+
+func (x *BaseTest) runRecovered(boundary func()) (escaped bool, value interface{}) {
+	defer func() {
+		if value = recover(); value != nil {
+			escaped = true
+		}
+	}()
+	boundary()
+	return false, nil
+}
+
+// Wraps a handler that panics with panicValue in the middleware, serves a
+// request through it and fails the test unless the panic was recovered and
+// converted to a 500 response.  Returns the recorded response.
+func (x *BaseTest) ExpectRecoveredHTTP(middleware func(http.Handler) http.Handler, panicValue interface{}) *httptest.ResponseRecorder {
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(panicValue)
+	})
+	rec := httptest.NewRecorder()
+	x.ExpectRecovered(func() {
+		middleware(panicking).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	}, panicValue)
+	x.Expect(rec.Code).Should(Equal(http.StatusInternalServerError), "status of response after recovering %v", panicValue)
+	return rec
+}
+
+// Asserts the boundary's log output includes the recovered panic value
+// and a goroutine stack trace.
+func (r *RecoveryAssert) LoggedStack(logged string) *RecoveryAssert {
+	r.x.Expect(logged).Should(ContainSubstring(fmt.Sprint(r.panicValue)), "log output should include the recovered panic value")
+	r.x.Expect(logged).Should(MatchRegexp(`goroutine \d+|\.go:\d+`), "log output should include the stack of the recovered panic")
+	return r
+}
+
+// Asserts the error the boundary returned in place of the panic mentions the panic value.
+func (r *RecoveryAssert) ReturnedError(err error) *RecoveryAssert {
+	r.x.Expect(err).Should(HaveOccurred(), "expected the recovered panic to be returned as an error")
+	r.x.Expect(err.Error()).Should(ContainSubstring(fmt.Sprint(r.panicValue)), "error returned for the recovered panic")
+	return r
+}
+
+// Runs fn and fails the test, naming the calling function, unless fn panics.
+// Returns the recovered value for further matching.
+func (x *BaseTest) ExpectPanic(fn func()) interface{} {
+	x.t.Helper()
+	panicked, value := x.runRecovered(fn)
+	if !panicked {
+		x.Fatalf("expected a panic in %s but the function returned normally", x.callerLogString())
+	}
+	return value
+}
+
+// Runs fn and fails the test, naming the calling function and the recovered
+// value, if fn panics.
+func (x *BaseTest) ExpectNoPanic(fn func()) {
+	x.t.Helper()
+	if panicked, value := x.runRecovered(fn); panicked {
+		x.Fatalf("unexpected panic in %s: %v", x.callerLogString(), value)
+	}
+}
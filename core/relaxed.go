@@ -0,0 +1,46 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/sbernheim/goonit/mock"
+)
+
+// Relaxes the generated mocks so calls no expectation matches return zero
+// values and are recorded, for UnexpectedCalls and Verify, instead of
+// failing the test.  Set a mock's expectations before relaxing it, since
+// gomock tries expectations in the order they were set and ones set later
+// are never reached.
+func (x *BaseTest) Relax(mocks ...interface{}) *BaseTest {
+	for _, m := range mocks {
+		mock.Relax(m, func(call mock.UnexpectedCall) {
+			x.mockCallsMu.Lock()
+			x.unexpected = append(x.unexpected, call)
+			x.mockCallsMu.Unlock()
+			x.recordMockCall(reflect.ValueOf(call.Receiver).Pointer(), call.Mock, call.Method, call.Args)
+		})
+	}
+	return x
+}
+
+// Returns the calls to relaxed mocks that no expectation matched, in order.
+func (x *BaseTest) UnexpectedCalls() []mock.UnexpectedCall {
+	x.mockCallsMu.Lock()
+	defer x.mockCallsMu.Unlock()
+	return append([]mock.UnexpectedCall{}, x.unexpected...)
+}
+
+// Fails the test if any relaxed mock was called without an expectation
+// matching the call.
+func (x *BaseTest) ExpectNoUnexpectedCalls() *BaseTest {
+	calls := x.UnexpectedCalls()
+	if len(calls) > 0 {
+		lines := make([]string, len(calls))
+		for i, call := range calls {
+			lines[i] = "  " + call.String()
+		}
+		x.Fatalf("relaxed mocks had %d unexpected calls:\n%s", len(calls), strings.Join(lines, "\n"))
+	}
+	return x
+}
@@ -0,0 +1,25 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRelaxRecordsVariadicCallsInFull(t *testing.T) {
+	x := New(t)
+	l := x.MockLogr()
+	x.Relax(l)
+	l.Info("none")
+	l.Info("one", "k")
+	l.Info("many", "a", 1, "b", 2)
+	calls := x.UnexpectedCalls()
+	expected := [][]interface{}{{"none"}, {"one", "k"}, {"many", "a", 1, "b", 2}}
+	if len(calls) != len(expected) {
+		t.Fatalf("recorded %v", calls)
+	}
+	for i, call := range calls {
+		if call.Method != "Info" || !reflect.DeepEqual(call.Args, expected[i]) {
+			t.Errorf("call %d recorded as %s, expected Info%v", i, call, expected[i])
+		}
+	}
+}
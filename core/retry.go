@@ -0,0 +1,41 @@
+package core
+
+import (
+	"fmt"
+)
+
+// Runs the body up to n times, each attempt with a fresh BaseTest, until an
+// attempt passes.  Fails the test with every attempt's failure output only
+// if all the attempts fail.  This is an escape hatch for known-flaky paths,
+// so the attempt that passed is always logged.
+func (x *BaseTest) Retry(n int, body func(x *BaseTest)) {
+	failures := []string{}
+	for attempt := 1; attempt <= n; attempt++ {
+		p := newProbeT(x.t.Name()).run(func(p *probeT) {
+			attemptX := NewT(p)
+			defer attemptX.Done()
+			body(attemptX)
+		})
+		if p.Skipped() {
+			x.t.Skipf("skipped on attempt %d of %d: %s", attempt, n, p.output())
+		}
+		if !p.Failed() {
+			if len(failures) > 0 {
+				x.Logf("passed on attempt %d of %d after failures:\n%s", attempt, n, joinAttempts(failures))
+			} else {
+				x.Logf("passed on attempt %d of %d", attempt, n)
+			}
+			return
+		}
+		failures = append(failures, p.output())
+	}
+	x.Fatalf("failed all %d attempts:\n%s", n, joinAttempts(failures))
+}
+
+func joinAttempts(failures []string) string {
+	joined := ""
+	for i, failure := range failures {
+		joined += fmt.Sprintf("--- attempt %d\n%s\n", i+1, failure)
+	}
+	return joined
+}
@@ -0,0 +1,27 @@
+package core
+
+import "testing"
+
+func TestRetryLogsFirstAttemptPassing(t *testing.T) {
+	lt := &lookalikeT{T: t}
+	x := NewT(lt, WithoutMocks())
+	x.Retry(3, func(x *BaseTest) {})
+	if !lt.logged("passed on attempt 1 of 3") || lt.logged("after failures") {
+		t.Errorf("logged %v", lt.logs)
+	}
+}
+
+func TestRetryLogsEarlierFailures(t *testing.T) {
+	lt := &lookalikeT{T: t}
+	x := NewT(lt, WithoutMocks())
+	attempts := 0
+	x.Retry(3, func(x *BaseTest) {
+		attempts++
+		if attempts < 2 {
+			x.Fatalf("flaked")
+		}
+	})
+	if !lt.logged("passed on attempt 2 of 3 after failures:\n--- attempt 1\n") || !lt.logged("flaked") {
+		t.Errorf("logged %v", lt.logs)
+	}
+}
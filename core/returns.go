@@ -0,0 +1,85 @@
+package core
+
+import (
+	"reflect"
+
+	"github.com/golang/mock/gomock"
+)
+
+// Returned records the values a mocked call returned to the code under test.
+type Returned struct {
+	Call   string
+	Values []interface{}
+}
+
+// Makes the expected call return the values, like call.Return, and records
+// them each time the mock is called so tests can correlate what the mock
+// returned, including errors from ErrFor, with what the code did next.
+// Pass the mocked method itself, e.g. m.Get, so the returns can be typed.
+//
+//	x.CaptureReturns(m.EXPECT().Get("id"), m.Get, user, x.ErrFor("Get"))
+func (x *BaseTest) CaptureReturns(call *gomock.Call, method interface{}, returns ...interface{}) *gomock.Call {
+	methodType := reflect.TypeOf(method)
+	if methodType == nil || methodType.Kind() != reflect.Func {
+		x.Fatalf("CaptureReturns needs the mocked method, got %T", method)
+	}
+	rets := x.returnValues("CaptureReturns", methodType, returns)
+	return call.DoAndReturn(reflect.MakeFunc(methodType, func([]reflect.Value) []reflect.Value {
+		x.recordReturns(returns)
+		return rets
+	}).Interface())
+}
+
+// Returns the values typed as the method's results, failing the test on
+// behalf of the caller if they don't fit.
+func (x *BaseTest) returnValues(caller string, methodType reflect.Type, returns []interface{}) []reflect.Value {
+	if methodType.NumOut() != len(returns) {
+		x.Fatalf("%s got %d return values for %s which returns %d", caller, len(returns), methodType, methodType.NumOut())
+	}
+	rets := make([]reflect.Value, len(returns))
+	for i, ret := range returns {
+		rets[i] = reflect.New(methodType.Out(i)).Elem()
+		if ret == nil {
+			continue
+		}
+		if !reflect.TypeOf(ret).AssignableTo(methodType.Out(i)) {
+			x.Fatalf("%s value %d of type %T cannot be returned as %s", caller, i, ret, methodType.Out(i))
+		}
+		rets[i].Set(reflect.ValueOf(ret))
+	}
+	return rets
+}
+
+func (x *BaseTest) recordReturns(returns []interface{}) {
+	stack := x.BuildCallerStack()
+	call := "unknown call"
+	if stack.Mocked != nil && stack.Mocker != nil {
+		call = stack.MockedCall()
+	} else if stack.Caller != nil {
+		call = stack.Caller.LogString()
+	}
+	x.capMu.Lock()
+	x.returned = append(x.returned, Returned{Call: call, Values: returns})
+	x.capMu.Unlock()
+}
+
+// Returns what every call set up with CaptureReturns returned, in call order.
+func (x *BaseTest) AllReturned() []Returned {
+	x.capMu.Lock()
+	defer x.capMu.Unlock()
+	return append([]Returned{}, x.returned...)
+}
+
+// Returns the values each call of the mocked call returned, named as for CapturedFrom.
+func (x *BaseTest) ReturnedFrom(mockCall string) [][]interface{} {
+	values := [][]interface{}{}
+	for _, r := range x.AllReturned() {
+		if r.Call == mockCall {
+			values = append(values, append([]interface{}{}, r.Values...))
+		}
+	}
+	if len(values) == 0 {
+		x.Fatalf("at %s there were no returns captured from mock call '%s'!", x.GetCallerInfo().LogString(), mockCall)
+	}
+	return values
+}
@@ -0,0 +1,25 @@
+package core
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestStubRecordsReturnsFromConcurrentCalls(t *testing.T) {
+	x := New(t)
+	l := x.MockLogr()
+	x.Stub(l.EXPECT().Enabled()).Return(true).Times(8)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.Enabled()
+			x.AllReturned()
+		}()
+	}
+	wg.Wait()
+	if returned := x.AllReturned(); len(returned) != 8 {
+		t.Fatalf("recorded %d returns, expected 8", len(returned))
+	}
+}
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"fmt"
+	"runtime/metrics"
+	"sort"
+	"strings"
+)
+
+// Names of the runtime/metrics RuntimeMetricsDelta reads by default.
+const (
+	GCCyclesMetric          = "/gc/cycles/total:gc-cycles"
+	GoroutinesCreatedMetric = "/sched/goroutines-created:goroutines"
+	HeapAllocBytesMetric    = "/gc/heap/allocs:bytes"
+	HeapAllocObjectsMetric  = "/gc/heap/allocs:objects"
+)
+
+// RuntimeDelta holds how much runtime metrics changed while a function ran.
+type RuntimeDelta struct {
+	x      *BaseTest
+	deltas map[string]float64
+}
+
+// Runs fn and returns how much each runtime metric changed, reading GC
+// cycles, goroutines created and heap allocations if no names are given.
+// Defaults the Go version does not support are left out; names given
+// explicitly must be supported.  Other goroutines in the test binary count
+// too, so use the delta as a coarse regression guard, not an exact
+// measurement.
+func (x *BaseTest) RuntimeMetricsDelta(fn func(), names ...string) *RuntimeDelta {
+	explicit := len(names) > 0
+	if !explicit {
+		names = []string{GCCyclesMetric, GoroutinesCreatedMetric, HeapAllocBytesMetric, HeapAllocObjectsMetric}
+	}
+	samples := make([]metrics.Sample, len(names))
+	for i, name := range names {
+		samples[i].Name = name
+	}
+	metrics.Read(samples)
+	before := x.sampleValues(samples, explicit)
+	fn()
+	metrics.Read(samples)
+	after := x.sampleValues(samples, explicit)
+	d := &RuntimeDelta{x: x, deltas: map[string]float64{}}
+	for name, val := range after {
+		d.deltas[name] = val - before[name]
+	}
+	return d
+}
+
+func (x *BaseTest) sampleValues(samples []metrics.Sample, explicit bool) map[string]float64 {
+	values := map[string]float64{}
+	for _, s := range samples {
+		switch s.Value.Kind() {
+		case metrics.KindUint64:
+			values[s.Name] = float64(s.Value.Uint64())
+		case metrics.KindFloat64:
+			values[s.Name] = s.Value.Float64()
+		case metrics.KindBad:
+			if explicit {
+				x.Fatalf("runtime metric '%s' is not supported by this Go version", s.Name)
+			}
+		default:
+			x.Fatalf("runtime metric '%s' is not a number", s.Name)
+		}
+	}
+	return values
+}
+
+// Returns the change in the named metric.
+func (d *RuntimeDelta) Get(name string) float64 {
+	val, found := d.deltas[name]
+	if !found {
+		d.x.Fatalf("runtime metric '%s' was not read; pass it to RuntimeMetricsDelta", name)
+	}
+	return val
+}
+
+func (d *RuntimeDelta) GCCycles() uint64 {
+	return uint64(d.Get(GCCyclesMetric))
+}
+
+func (d *RuntimeDelta) GoroutinesCreated() uint64 {
+	return uint64(d.Get(GoroutinesCreatedMetric))
+}
+
+func (d *RuntimeDelta) BytesAllocated() uint64 {
+	return uint64(d.Get(HeapAllocBytesMetric))
+}
+
+func (d *RuntimeDelta) ObjectsAllocated() uint64 {
+	return uint64(d.Get(HeapAllocObjectsMetric))
+}
+
+// Fails the test if the named metric grew by more than max.
+func (d *RuntimeDelta) ExpectAtMost(name string, max float64) *RuntimeDelta {
+	if val := d.Get(name); val > max {
+		d.x.Fatalf("expected runtime metric '%s' to change by at most %v but it changed by %v", name, max, val)
+	}
+	return d
+}
+
+func (d *RuntimeDelta) ExpectMaxGCCycles(max uint64) *RuntimeDelta {
+	return d.ExpectAtMost(GCCyclesMetric, float64(max))
+}
+
+func (d *RuntimeDelta) ExpectMaxGoroutinesCreated(max uint64) *RuntimeDelta {
+	return d.ExpectAtMost(GoroutinesCreatedMetric, float64(max))
+}
+
+func (d *RuntimeDelta) ExpectMaxBytesAllocated(max uint64) *RuntimeDelta {
+	return d.ExpectAtMost(HeapAllocBytesMetric, float64(max))
+}
+
+func (d *RuntimeDelta) ExpectMaxObjectsAllocated(max uint64) *RuntimeDelta {
+	return d.ExpectAtMost(HeapAllocObjectsMetric, float64(max))
+}
+
+func (d *RuntimeDelta) String() string {
+	names := make([]string, 0, len(d.deltas))
+	for name := range d.deltas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	b := &strings.Builder{}
+	for _, name := range names {
+		if b.Len() > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(b, "%s %+g", name, d.deltas[name])
+	}
+	return b.String()
+}
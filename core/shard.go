@@ -0,0 +1,59 @@
+package core
+
+import (
+	"hash/fnv"
+	"os"
+	"strconv"
+)
+
+// Set GOONIT_SHARD_TOTAL to the number of CI machines splitting the suite and
+// GOONIT_SHARD_INDEX to this machine's shard, from 0 to GOONIT_SHARD_TOTAL-1.
+const (
+	ShardIndexEnv = "GOONIT_SHARD_INDEX"
+	ShardTotalEnv = "GOONIT_SHARD_TOTAL"
+)
+
+// Returns the shard the named test or case belongs to.  The same name
+// always lands in the same shard, wherever it runs.
+func shardOf(name string, total int) int {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return int(h.Sum32() % uint32(total))
+}
+
+// Returns the shard index and total from the environment,
+// or 0 and 1 when the suite is not sharded.
+func ShardFromEnv() (index, total int) {
+	total, err := strconv.Atoi(os.Getenv(ShardTotalEnv))
+	if err != nil || total < 1 {
+		return 0, 1
+	}
+	index, err = strconv.Atoi(os.Getenv(ShardIndexEnv))
+	if err != nil || index < 0 || index >= total {
+		return 0, 1
+	}
+	return index, total
+}
+
+// Returns true if the named test or table case belongs to the shard
+// configured in the environment.
+func InShard(name string) bool {
+	index, total := ShardFromEnv()
+	return shardOf(name, total) == index
+}
+
+// Skips the test unless it belongs to the shard with the index of total shards.
+func Shard(t T, index, total int) {
+	if total < 1 || index < 0 || index >= total {
+		t.Fatalf("invalid shard %d of %d", index, total)
+	}
+	if shard := shardOf(t.Name(), total); shard != index {
+		t.Skipf("belongs to shard %d of %d, running shard %d", shard, total, index)
+	}
+}
+
+// Skips the test unless it belongs to the shard configured in the environment.
+func ShardEnv(t T) {
+	index, total := ShardFromEnv()
+	Shard(t, index, total)
+}
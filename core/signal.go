@@ -0,0 +1,210 @@
+package core
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+type signalTrap struct {
+	mu   sync.Mutex
+	regs []*signalReg
+}
+
+// A channel the code under test registered through the trap, with the
+// relay channel registered with the signal package in its place.
+// A registration for no signals in particular, which signal.Notify takes
+// to mean every signal, has all set, and keeps the signals reset for it
+// since in except.
+type signalReg struct {
+	c         chan<- os.Signal
+	sigs      map[os.Signal]bool
+	all       bool
+	except    map[os.Signal]bool
+	relay     chan os.Signal
+	done      chan struct{}
+	delivered map[os.Signal]int
+}
+
+// Reports whether the registration receives the signal.
+func (reg *signalReg) handles(sig os.Signal) bool {
+	if reg.all {
+		return !reg.except[sig]
+	}
+	return reg.sigs[sig]
+}
+
+// Returns functions to inject into the code under test in place of
+// signal.Notify and signal.Reset, and sets each of the variables, such as a
+// package's `var notify = signal.Notify`, to the matching one until the test
+// is Done.  Registrations made through them are undone when the test is
+// Done, and let ExpectSignalHandled see when a handler receives a signal.
+func (x *BaseTest) TrapSignals(notifyVars ...*func(c chan<- os.Signal, sig ...os.Signal)) (notify func(c chan<- os.Signal, sig ...os.Signal), reset func(sig ...os.Signal)) {
+	if x.signalTrap == nil {
+		x.signalTrap = &signalTrap{}
+		x.DoAfter(x.signalTrap.stopAll)
+	}
+	trap := x.signalTrap
+	for _, v := range notifyVars {
+		v := v
+		original := *v
+		x.DoAfter(func() { *v = original })
+		*v = trap.notify
+	}
+	return trap.notify, trap.reset
+}
+
+// Sets the variables, such as a package's `var resetSignals = signal.Reset`,
+// to the trap's reset until the test is Done.
+func (x *BaseTest) TrapSignalReset(resetVars ...*func(sig ...os.Signal)) {
+	_, reset := x.TrapSignals()
+	for _, v := range resetVars {
+		v := v
+		original := *v
+		x.DoAfter(func() { *v = original })
+		*v = reset
+	}
+}
+
+func (trap *signalTrap) notify(c chan<- os.Signal, sigs ...os.Signal) {
+	reg := &signalReg{
+		c:         c,
+		sigs:      map[os.Signal]bool{},
+		all:       len(sigs) == 0,
+		except:    map[os.Signal]bool{},
+		relay:     make(chan os.Signal, 1),
+		done:      make(chan struct{}),
+		delivered: map[os.Signal]int{},
+	}
+	for _, sig := range sigs {
+		reg.sigs[sig] = true
+	}
+	trap.mu.Lock()
+	trap.regs = append(trap.regs, reg)
+	trap.mu.Unlock()
+	signal.Notify(reg.relay, sigs...)
+	go trap.forward(reg)
+}
+
+func (trap *signalTrap) forward(reg *signalReg) {
+	for {
+		select {
+		case sig := <-reg.relay:
+			trap.mu.Lock()
+			handles := reg.handles(sig)
+			trap.mu.Unlock()
+			if !handles {
+				continue
+			}
+			select {
+			case reg.c <- sig:
+				trap.mu.Lock()
+				reg.delivered[sig]++
+				trap.mu.Unlock()
+			default:
+				// Dropped, as the signal package drops signals for a full channel.
+			}
+		case <-reg.done:
+			return
+		}
+	}
+}
+
+// Stops the trapped registrations from receiving the signals, or any
+// signal if none are given.  Handlers registered outside the trap keep
+// receiving them.
+func (trap *signalTrap) reset(sigs ...os.Signal) {
+	trap.mu.Lock()
+	defer trap.mu.Unlock()
+	kept := trap.regs[:0]
+	for _, reg := range trap.regs {
+		if reg.all && len(sigs) > 0 {
+			// The relay stays registered for every signal, and forward drops
+			// the ones reset.
+			for _, sig := range sigs {
+				reg.except[sig] = true
+			}
+			kept = append(kept, reg)
+			continue
+		}
+		for _, sig := range sigs {
+			delete(reg.sigs, sig)
+		}
+		signal.Stop(reg.relay)
+		if len(sigs) == 0 || len(reg.sigs) == 0 {
+			close(reg.done)
+			continue
+		}
+		remaining := make([]os.Signal, 0, len(reg.sigs))
+		for sig := range reg.sigs {
+			remaining = append(remaining, sig)
+		}
+		signal.Notify(reg.relay, remaining...)
+		kept = append(kept, reg)
+	}
+	trap.regs = kept
+}
+
+func (trap *signalTrap) stopAll() {
+	trap.reset()
+}
+
+// Returns how many times the signal has been delivered to trapped
+// handlers, how many handlers are registered for it, and whether they
+// have all received what was delivered.
+func (trap *signalTrap) status(sig os.Signal) (delivered, handlers int, drained bool) {
+	trap.mu.Lock()
+	defer trap.mu.Unlock()
+	drained = true
+	for _, reg := range trap.regs {
+		if reg.handles(sig) {
+			handlers++
+			delivered += reg.delivered[sig]
+			if len(reg.c) > 0 {
+				drained = false
+			}
+		}
+	}
+	return delivered, handlers, drained
+}
+
+// Sends the signal to the test process.  The signal package is told to
+// catch it until the test is Done, so a signal with no handler yet, such
+// as SIGTERM, does not end the test binary.
+func (x *BaseTest) SendSignal(sig os.Signal) {
+	guard := make(chan os.Signal, 1)
+	signal.Notify(guard, sig)
+	x.DoAfter(func() { signal.Stop(guard) })
+	p, err := os.FindProcess(os.Getpid())
+	if err == nil {
+		err = p.Signal(sig)
+	}
+	if err != nil {
+		x.Fatalf("failed to send signal %s to the test process: %s", sig, err.Error())
+	}
+}
+
+// Sends the signal and fails the test unless a handler registered through
+// TrapSignals receives it within the duration.
+func (x *BaseTest) ExpectSignalHandled(sig os.Signal, within time.Duration) {
+	if x.signalTrap == nil {
+		x.Fatalf("ExpectSignalHandled needs the code under test to register its handler with the notify TrapSignals returns")
+	}
+	before, handlers, _ := x.signalTrap.status(sig)
+	if handlers == 0 {
+		x.Fatalf("no handler is registered for signal %s", sig)
+	}
+	x.SendSignal(sig)
+	deadline := time.Now().Add(within)
+	for {
+		delivered, _, drained := x.signalTrap.status(sig)
+		if delivered > before && drained {
+			return
+		}
+		if time.Now().After(deadline) {
+			x.Fatalf("signal %s was not handled within %s", sig, within)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,35 @@
+//go:build !windows
+
+package core
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestTrapSignalsTreatsNoSignalsAsEverySignal(t *testing.T) {
+	x := New(t, WithoutMocks())
+	defer x.Done()
+	notify, reset := x.TrapSignals()
+	c := make(chan os.Signal, 1)
+	notify(c)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-c:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	x.ExpectSignalHandled(syscall.SIGUSR1, time.Second)
+	reset(syscall.SIGUSR1)
+	if _, handlers, _ := x.signalTrap.status(syscall.SIGUSR1); handlers != 0 {
+		t.Errorf("%d handlers still receive the reset signal", handlers)
+	}
+	x.ExpectSignalHandled(syscall.SIGUSR2, time.Second)
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Skips the test with the same message format for every goonit guard.
+func (x *BaseTest) skip(reason string, args ...interface{}) {
+	x.t.Helper()
+	x.t.Skipf("goonit: skipping "+reason, args...)
+}
+
+// Skips the test when tests run with -short.
+func (x *BaseTest) SkipIfShort() {
+	if testing.Short() {
+		x.skip("in short mode")
+	}
+}
+
+// Skips the test unless the environment variable is set to a non-empty value.
+func (x *BaseTest) SkipUnlessEnv(name string) {
+	if x.Getenv(name) == "" {
+		x.skip("because %s is not set", name)
+	}
+}
+
+// Skips the test when it runs on any of the operating systems, as named by GOOS.
+func (x *BaseTest) SkipOnOS(goos ...string) {
+	for _, name := range goos {
+		if runtime.GOOS == name {
+			x.skip("on %s", name)
+		}
+	}
+}
+
+// Environment variables CI services set.
+var ciEnvs = []string{"CI", "GITHUB_ACTIONS", "GITLAB_CI", "BUILDKITE", "CIRCLECI", "JENKINS_URL", "TEAMCITY_VERSION", "TF_BUILD"}
+
+// Returns true when the tests seem to run on a CI service.
+func IsCI() bool {
+	for _, name := range ciEnvs {
+		if val := os.Getenv(name); val != "" && val != "false" && val != "0" {
+			return true
+		}
+	}
+	return false
+}
+
+// Skips the test when it runs on a CI service.
+func (x *BaseTest) SkipIfCI() {
+	if IsCI() {
+		x.skip("on CI")
+	}
+}
+
+// Set GOONIT_STRICT_PRECONDITIONS to a non-empty value to make the Require
+// preconditions fail tests instead of skipping them, e.g. on CI machines
+// that are supposed to provide everything.
+const StrictPreconditionsEnv = "GOONIT_STRICT_PRECONDITIONS"
+
+// How long RequireNetwork and RequireDocker wait before giving up.
+var PreconditionTimeout = 5 * time.Second
+
+func (x *BaseTest) unmet(reason string, args ...interface{}) {
+	x.t.Helper()
+	if os.Getenv(StrictPreconditionsEnv) != "" {
+		x.t.Fatalf("goonit: precondition failed: "+reason, args...)
+	}
+	x.skip(reason, args...)
+}
+
+// Returns the path of the executable, skipping the test if it is not on PATH.
+func (x *BaseTest) RequireBinary(name string) string {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		x.unmet("because %s is not on PATH", name)
+	}
+	return path
+}
+
+// Skips the test if a TCP connection to the address cannot be opened.
+func (x *BaseTest) RequireNetwork(address string) {
+	conn, err := net.DialTimeout("tcp", address, PreconditionTimeout)
+	if err != nil {
+		x.unmet("because %s is unreachable: %s", address, err.Error())
+		return
+	}
+	conn.Close()
+}
+
+// Skips the test if the Docker daemon does not answer.
+func (x *BaseTest) RequireDocker() {
+	docker := x.RequireBinary("docker")
+	ctx, cancel := context.WithTimeout(context.Background(), PreconditionTimeout)
+	defer cancel()
+	if out, err := exec.CommandContext(ctx, docker, "version", "--format", "{{.Server.Version}}").CombinedOutput(); err != nil {
+		x.unmet("because Docker is unavailable: %s", strings.TrimSpace(string(out)))
+	}
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	scenariosMu sync.Mutex
+	scenarios   = map[string]func(x *BaseTest){}
+)
+
+// Registers a named scenario that soak runs can select from a ScenarioMix.
+func RegisterScenario(name string, scenario func(x *BaseTest)) {
+	scenariosMu.Lock()
+	defer scenariosMu.Unlock()
+	scenarios[name] = scenario
+}
+
+func registeredScenario(name string) (func(x *BaseTest), bool) {
+	scenariosMu.Lock()
+	defer scenariosMu.Unlock()
+	scenario, found := scenarios[name]
+	return scenario, found
+}
+
+// Mix selects registered scenarios at random in proportion to their weights.
+type Mix struct {
+	names   []string
+	weights []float64
+	total   float64
+}
+
+// Returns a Mix of the registered scenarios named by the map keys,
+// each selected in proportion to its weight.
+func ScenarioMix(weights map[string]float64) *Mix {
+	m := &Mix{}
+	for name := range weights {
+		m.names = append(m.names, name)
+	}
+	// Sorted so the same seed always selects the same scenarios.
+	sort.Strings(m.names)
+	for _, name := range m.names {
+		m.weights = append(m.weights, weights[name])
+		m.total += weights[name]
+	}
+	return m
+}
+
+func (m *Mix) pick(r *rand.Rand) string {
+	n := r.Float64() * m.total
+	for i, w := range m.weights {
+		if n < w {
+			return m.names[i]
+		}
+		n -= w
+	}
+	return m.names[len(m.names)-1]
+}
+
+// Runs scenarios selected from the mix, each as a subtest with a fresh
+// BaseTest created from the options, until the duration has passed or a
+// scenario fails.  The seed is logged so -goonit.seed or GOONIT_SEED can
+// replay the run.
+func (m *Mix) Soak(t *testing.T, duration time.Duration, opts ...Option) {
+	for _, name := range m.names {
+		if _, found := registeredScenario(name); !found {
+			t.Fatalf("scenario '%s' in the mix is not registered", name)
+		}
+	}
+	if m.total <= 0 {
+		t.Fatalf("scenario mix %v has no positive weights", m.names)
+	}
+	seed := configuredSeed()
+	t.Logf("soak running scenarios %v for %s with GOONIT_SEED=%d", m.names, duration, seed)
+	r := rand.New(rand.NewSource(seed))
+	counts := map[string]int{}
+	deadline := time.Now().Add(duration)
+	for i := 1; time.Now().Before(deadline); i++ {
+		name := m.pick(r)
+		scenario, _ := registeredScenario(name)
+		counts[name]++
+		passed := t.Run(fmt.Sprintf("%d_%s", i, name), func(t *testing.T) {
+			x := New(t, opts...)
+			defer x.Done()
+			scenario(x)
+		})
+		if !passed {
+			t.Fatalf("soak stopped at iteration %d (%s) with GOONIT_SEED=%d", i, name, seed)
+		}
+	}
+	t.Logf("soak ran scenarios %v", counts)
+}
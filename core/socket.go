@@ -0,0 +1,45 @@
+package core
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// Unix domain socket paths are limited to 104 bytes on macOS and BSD,
+// 108 on Linux, including the terminating NUL.
+const maxSocketPath = 103
+
+// Returns a short unique path for a Unix domain socket.  The test's temp
+// directory usually makes socket paths too long, so the socket goes in its own
+// short-named directory under the system temp directory, or /tmp if that is
+// too long too.  The directory is removed when the test is Done.
+func (x *BaseTest) UnixSocketPath() string {
+	base := os.TempDir()
+	if len(filepath.Join(base, "goonit000000000", "s.sock")) > maxSocketPath {
+		base = "/tmp"
+	}
+	dir, err := ioutil.TempDir(base, "goonit")
+	if err != nil {
+		x.Fatalf("failed to create socket directory: %s", err.Error())
+	}
+	x.DoAfter(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "s.sock")
+	if len(path) > maxSocketPath {
+		x.Fatalf("socket path '%s' is longer than %d bytes", path, maxSocketPath)
+	}
+	return path
+}
+
+// Returns a listener on a new Unix domain socket that is closed
+// when the test is Done.  Its address is the socket path.
+func (x *BaseTest) UnixSocket() net.Listener {
+	path := x.UnixSocketPath()
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		x.Fatalf("failed to listen on unix socket '%s': %s", path, err.Error())
+	}
+	x.DoAfter(func() { l.Close() })
+	return l
+}
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+)
+
+// SoftAssert collects assertion failures instead of stopping at the first one.
+type SoftAssert struct {
+	failures []string
+}
+
+// Starts an assertion whose failure is collected rather than reported.
+func (s *SoftAssert) Expect(actual interface{}, extra ...interface{}) types.Assertion {
+	return NewGomega(func(message string, _ ...int) {
+		s.failures = append(s.failures, message)
+	}).Expect(actual, extra...)
+}
+
+// Returns the failure messages collected so far.
+func (s *SoftAssert) Failures() []string {
+	return s.failures
+}
+
+// Runs the block, then fails the test with every assertion failure the block
+// collected, so one run shows all the mismatches in a large struct or response.
+func (x *BaseTest) Softly(block func(s *SoftAssert)) {
+	s := &SoftAssert{}
+	block(s)
+	if len(s.failures) == 0 {
+		return
+	}
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "%d soft assertions failed:", len(s.failures))
+	for i, failure := range s.failures {
+		fmt.Fprintf(b, "\n%d) %s", i+1, strings.TrimSpace(failure))
+	}
+	x.Fatalf("%s", b.String())
+}
@@ -0,0 +1,17 @@
+package core
+
+import (
+	"reflect"
+
+	"github.com/sbernheim/goonit/mock"
+)
+
+// Records a call from a spy made with mock.Spy: its arguments and results
+// are captured as Capture does, under the name of the mocked call, its
+// results recorded as CaptureReturns does, and the call recorded for
+// Verify.
+func (x *BaseTest) RecordSpiedCall(call mock.SpiedCall) {
+	x.Capture(append(append([]interface{}{}, call.Args...), call.Results...)...)
+	x.recordReturns(call.Results)
+	x.recordMockCall(reflect.ValueOf(call.Receiver).Pointer(), call.Mock, call.Method, call.Args)
+}
@@ -0,0 +1,303 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// DBTracker samples a database's connection pool stats while the test runs
+// and, for databases opened with OpenTrackedDB, counts open prepared statements.
+type DBTracker struct {
+	x        *BaseTest
+	db       *sql.DB
+	mu       sync.Mutex
+	maxOpen  int
+	maxInUse int
+	stmts    *int64
+	stop     chan struct{}
+	stopped  chan struct{}
+}
+
+// How often a DBTracker samples the pool stats.
+var DBStatsInterval = 5 * time.Millisecond
+
+// Tracks the database's pool stats until the test is Done, then fails the
+// test if any connection is still in use, e.g. by unclosed Rows or an
+// uncommitted Tx.
+func (x *BaseTest) TrackDBStats(db *sql.DB) *DBTracker {
+	return x.trackDB(db, nil)
+}
+
+func (x *BaseTest) trackDB(db *sql.DB, stmts *int64) *DBTracker {
+	d := &DBTracker{x: x, db: db, stmts: stmts, stop: make(chan struct{}), stopped: make(chan struct{})}
+	x.dbTrackers = append(x.dbTrackers, d)
+	go d.sample()
+	x.DoAfter(d.verify)
+	return d
+}
+
+func (d *DBTracker) record() {
+	stats := d.db.Stats()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if stats.OpenConnections > d.maxOpen {
+		d.maxOpen = stats.OpenConnections
+	}
+	if stats.InUse > d.maxInUse {
+		d.maxInUse = stats.InUse
+	}
+}
+
+func (d *DBTracker) sample() {
+	defer close(d.stopped)
+	ticker := time.NewTicker(DBStatsInterval)
+	defer ticker.Stop()
+	for {
+		d.record()
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Returns the most connections the pool held open and in use at once.
+func (d *DBTracker) Max() (open, inUse int) {
+	d.record()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.maxOpen, d.maxInUse
+}
+
+// Returns how many prepared statements are open, or -1 if the database
+// was not opened with OpenTrackedDB.
+func (d *DBTracker) OpenStatements() int64 {
+	if d.stmts == nil {
+		return -1
+	}
+	return atomic.LoadInt64(d.stmts)
+}
+
+func (d *DBTracker) ExpectMaxOpenConns(n int) *DBTracker {
+	open, _ := d.Max()
+	d.x.Expect(open).Should(BeNumerically("<=", n), "the connection pool opened more connections than expected")
+	return d
+}
+
+func (d *DBTracker) verify() {
+	close(d.stop)
+	<-d.stopped
+	d.x.Expect(d.db.Stats().InUse).Should(BeZero(), "database connections are still in use; are Rows, Stmts or Txs left open?")
+	if d.stmts != nil {
+		d.x.Expect(d.OpenStatements()).Should(BeZero(), "prepared statements were not closed")
+	}
+}
+
+// Fails the test if any tracked database opened more than n connections at once.
+func (x *BaseTest) ExpectMaxOpenConns(n int) {
+	if len(x.dbTrackers) == 0 {
+		x.Fatalf("ExpectMaxOpenConns needs a database tracked with TrackDBStats or OpenTrackedDB")
+	}
+	for _, d := range x.dbTrackers {
+		d.ExpectMaxOpenConns(n)
+	}
+}
+
+// Opens the database through a driver wrapper that counts prepared
+// statements, tracks it with TrackDBStats and closes it when the test is Done.
+// Done also fails the test if any prepared statement was left open.
+func (x *BaseTest) OpenTrackedDB(driverName, dsn string) (*sql.DB, *DBTracker) {
+	probe, err := sql.Open(driverName, dsn)
+	if err != nil {
+		x.Fatalf("failed to open database with driver '%s': %s", driverName, err.Error())
+	}
+	drv := probe.Driver()
+	probe.Close()
+	stmts := new(int64)
+	db := sql.OpenDB(&countingConnector{driver: drv, dsn: dsn, stmts: stmts})
+	tracker := x.trackDB(db, stmts)
+	x.DoAfter(func() { db.Close() })
+	return db, tracker
+}
+
+type countingConnector struct {
+	driver driver.Driver
+	dsn    string
+	stmts  *int64
+}
+
+func (c *countingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	var conn driver.Conn
+	var err error
+	if dc, ok := c.driver.(driver.DriverContext); ok {
+		var connector driver.Connector
+		if connector, err = dc.OpenConnector(c.dsn); err == nil {
+			conn, err = connector.Connect(ctx)
+		}
+	} else {
+		conn, err = c.driver.Open(c.dsn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &countingConn{Conn: conn, stmts: c.stmts}, nil
+}
+
+func (c *countingConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// countingConn hides the driver's direct query and exec support, so every
+// statement goes through Prepare, where it is counted until closed.
+type countingConn struct {
+	driver.Conn
+	stmts *int64
+}
+
+func (c *countingConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *countingConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if pc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = pc.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	atomic.AddInt64(c.stmts, 1)
+	return &countingStmt{Stmt: stmt, conn: c.Conn, stmts: c.stmts}, nil
+}
+
+func (c *countingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if bc, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return bc.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+// The optional interfaces below are forwarded to the driver's connection,
+// doing what database/sql does without them when the connection doesn't
+// implement them, so wrapping the connection changes nothing but the
+// counting.
+
+func (c *countingConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *countingConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *countingConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *countingConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type countingStmt struct {
+	driver.Stmt
+	conn   driver.Conn
+	stmts  *int64
+	closed int32
+}
+
+// Checks the argument the way database/sql would with the driver's own
+// statement: through the statement's NamedValueChecker, or else the
+// connection's, then its ColumnConverter, falling back to the default
+// conversion with driver.ErrSkip.
+func (s *countingStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		if err := nvc.CheckNamedValue(nv); err != driver.ErrSkip {
+			return err
+		}
+	} else if nvc, ok := s.conn.(driver.NamedValueChecker); ok {
+		if err := nvc.CheckNamedValue(nv); err != driver.ErrSkip {
+			return err
+		}
+	}
+	cc, ok := s.Stmt.(driver.ColumnConverter)
+	if !ok {
+		return driver.ErrSkip
+	}
+	index := nv.Ordinal - 1
+	if want := s.Stmt.NumInput(); want >= 0 && want <= index {
+		return nil
+	}
+	if vr, ok := nv.Value.(driver.Valuer); ok {
+		if rv := reflect.ValueOf(vr); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			nv.Value = nil
+		} else {
+			sv, err := vr.Value()
+			if err != nil {
+				return err
+			}
+			nv.Value = sv
+		}
+	}
+	arg := nv.Value
+	var err error
+	if nv.Value, err = cc.ColumnConverter(index).ConvertValue(arg); err != nil {
+		return err
+	}
+	if !driver.IsValue(nv.Value) {
+		return fmt.Errorf("driver ColumnConverter error converted %T to unsupported type %T", arg, nv.Value)
+	}
+	return nil
+}
+
+func (s *countingStmt) Close() error {
+	if atomic.CompareAndSwapInt32(&s.closed, 0, 1) {
+		atomic.AddInt64(s.stmts, -1)
+	}
+	return s.Stmt.Close()
+}
+
+func namedToValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
+
+func (s *countingStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if ec, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return ec.ExecContext(ctx, args)
+	}
+	return s.Stmt.Exec(namedToValues(args))
+}
+
+func (s *countingStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if qc, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return qc.QueryContext(ctx, args)
+	}
+	return s.Stmt.Query(namedToValues(args))
+}
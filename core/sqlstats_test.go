@@ -0,0 +1,127 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+// fakeDriver records the optional driver interfaces database/sql calls.
+type fakeDriver struct {
+	mu      sync.Mutex
+	pings   int
+	resets  int
+	valids  int
+	checked []interface{}
+	execs   [][]driver.Value
+}
+
+func (d *fakeDriver) count(n *int) {
+	d.mu.Lock()
+	*n++
+	d.mu.Unlock()
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) {
+	return &fakeConn{d: d}, nil
+}
+
+type fakeConn struct {
+	d *fakeDriver
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return &fakeStmt{d: c.d}, nil }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("no transactions") }
+func (c *fakeConn) Ping(context.Context) error          { c.d.count(&c.d.pings); return nil }
+func (c *fakeConn) ResetSession(context.Context) error  { c.d.count(&c.d.resets); return nil }
+func (c *fakeConn) IsValid() bool                       { c.d.count(&c.d.valids); return true }
+
+type point struct{ x, y int }
+
+// Accepts points, which the default conversion rejects.
+func (c *fakeConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if p, ok := nv.Value.(point); ok {
+		c.d.mu.Lock()
+		c.d.checked = append(c.d.checked, p)
+		c.d.mu.Unlock()
+		nv.Value = int64(p.x*100 + p.y)
+		return nil
+	}
+	return driver.ErrSkip
+}
+
+type fakeStmt struct {
+	d *fakeDriver
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	s.d.execs = append(s.d.execs, args)
+	s.d.mu.Unlock()
+	return driver.RowsAffected(1), nil
+}
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) { return fakeRows{}, nil }
+
+// Doubles ints, so the test sees the converter ran.
+func (s *fakeStmt) ColumnConverter(int) driver.ValueConverter { return doubler{} }
+
+type doubler struct{}
+
+func (doubler) ConvertValue(v interface{}) (driver.Value, error) {
+	if n, ok := v.(int); ok {
+		return int64(2 * n), nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(v)
+}
+
+type fakeRows struct{}
+
+func (fakeRows) Columns() []string         { return nil }
+func (fakeRows) Close() error              { return nil }
+func (fakeRows) Next([]driver.Value) error { return io.EOF }
+
+var fakeDrivers = map[string]*fakeDriver{}
+
+func openFakeDB(t *testing.T, x *BaseTest) (*sql.DB, *fakeDriver) {
+	d := &fakeDriver{}
+	name := "goonitfake" + t.Name()
+	sql.Register(name, d)
+	db, _ := x.OpenTrackedDB(name, "")
+	return db, d
+}
+
+func TestTrackedDBForwardsOptionalDriverInterfaces(t *testing.T) {
+	x := New(t)
+	defer x.Done()
+	db, d := openFakeDB(t, x)
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT", point{1, 2}); err != nil {
+		t.Fatalf("the connection's NamedValueChecker was not used: %s", err)
+	}
+	if _, err := db.Exec("INSERT", 21); err != nil {
+		t.Fatal(err)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.pings != 1 {
+		t.Errorf("Ping reached the driver %d times", d.pings)
+	}
+	if d.resets == 0 {
+		t.Errorf("ResetSession never reached the driver")
+	}
+	if d.valids == 0 {
+		t.Errorf("IsValid never reached the driver")
+	}
+	if len(d.execs) != 2 || d.execs[0][0] != int64(102) || d.execs[1][0] != int64(42) {
+		t.Errorf("driver executed with %v", d.execs)
+	}
+}
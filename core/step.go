@@ -0,0 +1,128 @@
+package core
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sbernheim/goonit/clock"
+)
+
+// StepScheduler makes the order in which concurrent workers proceed explicit.
+// Code under test calls Wait(n) at each point worker n must not pass until
+// the test calls Release(n), so fan-in and fan-out orderings can be forced
+// without sleeps.
+type StepScheduler struct {
+	x       *BaseTest
+	mu      sync.Mutex
+	tokens  map[int]int
+	waiting map[int]int
+	changed chan struct{}
+	closed  bool
+}
+
+// Returns a scheduler whose blocked workers are all let go when the test
+// is Done.
+func (x *BaseTest) StepScheduler() *StepScheduler {
+	s := &StepScheduler{
+		x:       x,
+		tokens:  map[int]int{},
+		waiting: map[int]int{},
+		changed: make(chan struct{}),
+	}
+	x.DoAfter(s.close)
+	return s
+}
+
+// Must be called with s.mu held.
+func (s *StepScheduler) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// Blocks the calling worker until the test releases it.  Returns at once
+// after the test is Done.
+func (s *StepScheduler) Wait(worker int) {
+	s.mu.Lock()
+	s.waiting[worker]++
+	s.notify()
+	for s.tokens[worker] == 0 && !s.closed {
+		changed := s.changed
+		s.mu.Unlock()
+		<-changed
+		s.mu.Lock()
+	}
+	if s.tokens[worker] > 0 {
+		s.tokens[worker]--
+	}
+	s.waiting[worker]--
+	s.notify()
+	s.mu.Unlock()
+}
+
+// Returns a func that waits as the worker, for injecting into code under
+// test as a hook.
+func (s *StepScheduler) Gate(worker int) func() {
+	return func() { s.Wait(worker) }
+}
+
+// Lets the worker pass one Wait, now or the next time it calls Wait.
+func (s *StepScheduler) Release(worker int) *StepScheduler {
+	s.mu.Lock()
+	s.tokens[worker]++
+	s.notify()
+	s.mu.Unlock()
+	return s
+}
+
+// Returns true if the worker is blocked in Wait.
+func (s *StepScheduler) Waiting(worker int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.waiting[worker] > 0 && s.tokens[worker] == 0
+}
+
+// Fails the test unless the worker is blocked in Wait within the duration.
+func (s *StepScheduler) AwaitWaiting(worker int, within time.Duration) *StepScheduler {
+	timeout := time.After(within)
+	for {
+		s.mu.Lock()
+		changed := s.changed
+		s.mu.Unlock()
+		if s.Waiting(worker) {
+			return s
+		}
+		select {
+		case <-changed:
+		case <-timeout:
+			s.x.Fatalf("worker %d did not reach a step within %s", worker, within)
+		}
+	}
+}
+
+// Releases the worker and fails the test unless it blocks at its next step
+// within the duration, so the test knows exactly how far the worker got.
+func (s *StepScheduler) Step(worker int, within time.Duration) *StepScheduler {
+	return s.Release(worker).AwaitWaiting(worker, within)
+}
+
+func (s *StepScheduler) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.notify()
+	s.mu.Unlock()
+}
+
+// Waits until the number of timer-driven workers are blocked on the fake
+// clock, then advances it, so workers woken by timers proceed one step at
+// a time like those released with Step.
+func (s *StepScheduler) AdvanceClock(c *clock.Fake, d time.Duration, waiters int, within time.Duration) *StepScheduler {
+	deadline := time.Now().Add(within)
+	for c.Waiters() < waiters {
+		if time.Now().After(deadline) {
+			s.x.Fatalf("%d workers did not wait on the clock within %s; %d did", waiters, within, c.Waiters())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	c.Advance(d)
+	return s
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"reflect"
+
+	"github.com/golang/mock/gomock"
+)
+
+// Stub sets what an expected mock call returns, capturing the arguments of
+// each call as Capture does, recording what it returned as CaptureReturns
+// does, and recording the call for Verify.
+//
+//	x.Stub(m.EXPECT().Get(match.AnyString())).Return(user).Times(2)
+type Stub struct {
+	x          *BaseTest
+	call       *gomock.Call
+	methodType reflect.Type
+	receiver   uintptr
+	mock       string
+	method     string
+}
+
+// Returns a stub for the expected call.
+func (x *BaseTest) Stub(call *gomock.Call) *Stub {
+	s := &Stub{x: x, call: call}
+	s.methodType, s.receiver, s.mock, s.method = x.mockedMethod(call)
+	return s
+}
+
+// Returns the type of the call's mocked method, without the receiver, and
+// which mock and method it is.  gomock keeps these unexported, so they are
+// read with reflection.
+func (x *BaseTest) mockedMethod(call *gomock.Call) (methodType reflect.Type, receiver uintptr, mock, method string) {
+	c := reflect.ValueOf(call).Elem()
+	r, name := c.FieldByName("receiver"), c.FieldByName("method")
+	if !r.IsValid() || !name.IsValid() || r.IsNil() {
+		x.Fatalf("failed to find the mocked method of call '%s'", call)
+	}
+	m, found := r.Elem().Type().MethodByName(name.String())
+	if !found {
+		x.Fatalf("failed to find method '%s' of %s", name.String(), r.Elem().Type())
+	}
+	in := make([]reflect.Type, m.Type.NumIn()-1)
+	for i := range in {
+		in[i] = m.Type.In(i + 1)
+	}
+	out := make([]reflect.Type, m.Type.NumOut())
+	for i := range out {
+		out[i] = m.Type.Out(i)
+	}
+	methodType = reflect.FuncOf(in, out, m.Type.IsVariadic())
+	return methodType, r.Elem().Pointer(), reflect.Indirect(r.Elem()).Type().Name(), name.String()
+}
+
+// Makes the call return the values.
+func (s *Stub) Return(returns ...interface{}) *Stub {
+	rets := s.x.returnValues("Stub", s.methodType, returns)
+	s.call.DoAndReturn(reflect.MakeFunc(s.methodType, func(args []reflect.Value) []reflect.Value {
+		values := argValues(s.methodType, args)
+		s.x.Capture(values...)
+		s.x.recordMockCall(s.receiver, s.mock, s.method, values)
+		s.x.recordReturns(returns)
+		return rets
+	}).Interface())
+	return s
+}
+
+// Makes the call return the error, and zero values before it.
+func (s *Stub) ReturnError(err error) *Stub {
+	n := s.methodType.NumOut()
+	if n == 0 || s.methodType.Out(n-1) != reflect.TypeOf((*error)(nil)).Elem() {
+		s.x.Fatalf("Stub cannot return an error from %s", s.methodType)
+	}
+	values := make([]interface{}, n)
+	for i := 0; i < n-1; i++ {
+		values[i] = reflect.Zero(s.methodType.Out(i)).Interface()
+	}
+	values[n-1] = err
+	return s.Return(values...)
+}
+
+func (s *Stub) Times(n int) *Stub {
+	s.call.Times(n)
+	return s
+}
+
+func (s *Stub) AnyTimes() *Stub {
+	s.call.AnyTimes()
+	return s
+}
+
+// Returns the stubbed call, for gomock settings the stub lacks.
+func (s *Stub) Call() *gomock.Call {
+	return s.call
+}
+
+// Returns the arguments as values, spreading out variadic ones.
+func argValues(methodType reflect.Type, args []reflect.Value) []interface{} {
+	values := []interface{}{}
+	for i, arg := range args {
+		if methodType.IsVariadic() && i == len(args)-1 {
+			for j := 0; j < arg.Len(); j++ {
+				values = append(values, arg.Index(j).Interface())
+			}
+			continue
+		}
+		values = append(values, arg.Interface())
+	}
+	return values
+}
@@ -0,0 +1,22 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestStubVariadicMethodRecordsSpreadArguments(t *testing.T) {
+	x := New(t)
+	l := x.MockLogr()
+	x.Stub(l.EXPECT().Info("saved", gomock.Any(), gomock.Any())).Return()
+	l.Info("saved", "id", 7)
+	calls := x.MockCalls()
+	if len(calls) != 1 || calls[0].Method != "Info" || !reflect.DeepEqual(calls[0].Args, []interface{}{"saved", "id", 7}) {
+		t.Fatalf("recorded %v", calls)
+	}
+	if id := x.CapturedOfType(0); !reflect.DeepEqual(id, []interface{}{7}) {
+		t.Errorf("captured %v, expected [7]", id)
+	}
+}
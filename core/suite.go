@@ -0,0 +1,84 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// A suite implements any of these lifecycle interfaces to run code around
+// the whole suite or around each of its test methods.
+type SuiteSetup interface {
+	SetupSuite(t *testing.T)
+}
+
+type SuiteTeardown interface {
+	TeardownSuite(t *testing.T)
+}
+
+type TestSetup interface {
+	SetupTest(x *BaseTest)
+}
+
+type TestTeardown interface {
+	TeardownTest(x *BaseTest)
+}
+
+var baseTestType = reflect.TypeOf((*BaseTest)(nil))
+
+func suiteTests(suite interface{}) []reflect.Method {
+	suiteType := reflect.TypeOf(suite)
+	tests := []reflect.Method{}
+	for i := 0; i < suiteType.NumMethod(); i++ {
+		m := suiteType.Method(i)
+		if !startsWith(m.Name, "Test") {
+			continue
+		}
+		if m.Type.NumIn() != 2 || m.Type.In(1) != baseTestType || m.Type.NumOut() != 0 {
+			continue
+		}
+		tests = append(tests, m)
+	}
+	return tests
+}
+
+// Runs every method of the suite named like a test that takes a *BaseTest
+// as a subtest with a fresh BaseTest created from the options.
+// SetupTest runs before and TeardownTest after each test method, before the
+// BaseTest is Done, and SetupSuite and TeardownSuite run once around them all.
+// The scenarios and expectations the tests declare are written as Markdown
+// to the GOONIT_DOCS directory when it is set.  Test methods outside the shard
+// set by GOONIT_SHARD_INDEX and GOONIT_SHARD_TOTAL are skipped.
+func RunSuite(t *testing.T, suite interface{}, opts ...Option) {
+	tests := suiteTests(suite)
+	if len(tests) == 0 {
+		t.Logf("suite %T has no test methods taking a *core.BaseTest", suite)
+		return
+	}
+	if s, ok := suite.(SuiteSetup); ok {
+		s.SetupSuite(t)
+	}
+	if s, ok := suite.(SuiteTeardown); ok {
+		defer s.TeardownSuite(t)
+	}
+	suiteValue := reflect.ValueOf(suite)
+	doc := &SuiteDoc{Suite: reflect.Indirect(suiteValue).Type().Name()}
+	for _, test := range tests {
+		method := test
+		t.Run(method.Name, func(t *testing.T) {
+			ShardEnv(t)
+			x := New(t, opts...)
+			defer func() { doc.Tests = append(doc.Tests, x.testDoc(method.Name)) }()
+			defer x.Done()
+			if s, ok := suite.(TestSetup); ok {
+				s.SetupTest(x)
+			}
+			if s, ok := suite.(TestTeardown); ok {
+				defer s.TeardownTest(x)
+			}
+			method.Func.Call([]reflect.Value{suiteValue, reflect.ValueOf(x)})
+		})
+	}
+	if err := doc.write(); err != nil {
+		t.Errorf("failed to write documentation for suite %s: %s", doc.Suite, err.Error())
+	}
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"github.com/go-logr/logr"
+)
+
+// T is the part of *testing.T a BaseTest uses.  Test frameworks that provide
+// a testing.T lookalike, such as Ginkgo's GinkgoT(), satisfy it too.
+type T interface {
+	Cleanup(func())
+	Setenv(key, value string)
+	Error(args ...interface{})
+	Errorf(format string, args ...interface{})
+	Fail()
+	FailNow()
+	Failed() bool
+	Fatal(args ...interface{})
+	Fatalf(format string, args ...interface{})
+	Helper()
+	Log(args ...interface{})
+	Logf(format string, args ...interface{})
+	Name() string
+	Parallel()
+	Skip(args ...interface{})
+	SkipNow()
+	Skipf(format string, args ...interface{})
+	Skipped() bool
+	TempDir() string
+}
+
+// tLogger is a logr.Logger that logs errors through a T, the same way
+// the logr testing package's TestLogger does for a *testing.T.
+type tLogger struct {
+	t T
+}
+
+var _ logr.Logger = &tLogger{}
+
+func (l *tLogger) Enabled() bool {
+	return false
+}
+
+func (l *tLogger) Info(msg string, keysAndValues ...interface{}) {
+}
+
+func (l *tLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.t.Logf("%s: %v -- %v", msg, err, keysAndValues)
+}
+
+func (l *tLogger) V(level int) logr.Logger {
+	return l
+}
+
+func (l *tLogger) WithName(name string) logr.Logger {
+	return l
+}
+
+func (l *tLogger) WithValues(keysAndValues ...interface{}) logr.Logger {
+	return l
+}
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// TaskTracker records the lifecycle of tasks spawned through an errgroup.Group,
+// a sync.WaitGroup or any similar fan-out so tests can verify that every task
+// completed, none outlived the group and the first error was the one returned.
+type TaskTracker struct {
+	x        *BaseTest
+	mu       sync.Mutex
+	running  map[string]int
+	started  int
+	finished int
+	errs     []error
+}
+
+// Returns a new TaskTracker that reports failures through this test.
+func (x *BaseTest) Tasks() *TaskTracker {
+	return &TaskTracker{
+		x:       x,
+		running: map[string]int{},
+		errs:    []error{},
+	}
+}
+
+func (tt *TaskTracker) start(name string) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	tt.started++
+	tt.running[name]++
+}
+
+func (tt *TaskTracker) finish(name string, err error) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	tt.finished++
+	tt.running[name]--
+	if tt.running[name] == 0 {
+		delete(tt.running, name)
+	}
+	if err != nil {
+		tt.errs = append(tt.errs, err)
+	}
+}
+
+// Wraps a task for errgroup.Group.Go so the tracker records when it starts,
+// finishes and what error it returns.
+func (tt *TaskTracker) Wrap(name string, task func() error) func() error {
+	return func() (err error) {
+		tt.start(name)
+		defer func() { tt.finish(name, err) }()
+		return task()
+	}
+}
+
+// Wraps a task started with `go` and a sync.WaitGroup.
+func (tt *TaskTracker) WrapFunc(name string, task func()) func() {
+	return func() {
+		tt.start(name)
+		defer tt.finish(name, nil)
+		task()
+	}
+}
+
+func (tt *TaskTracker) runningNames() []string {
+	names := make([]string, 0, len(tt.running))
+	for name := range tt.running {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Returns the number of tasks that have started and finished so far.
+func (tt *TaskTracker) Counts() (started, finished int) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	return tt.started, tt.finished
+}
+
+// Returns the errors tasks returned in the order they returned them.
+func (tt *TaskTracker) Errors() []error {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	return append([]error{}, tt.errs...)
+}
+
+// Fails the test unless every task that started has finished.
+// Call this after the group's Wait returns.
+func (tt *TaskTracker) ExpectAllCompleted() *TaskTracker {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	tt.x.Expect(tt.finished).Should(Equal(tt.started), "%d of %d tasks did not complete: %v", tt.started-tt.finished, tt.started, tt.runningNames())
+	return tt
+}
+
+// Fails the test unless all running tasks finish within the timeout, typically
+// after the group's context was canceled.  Tasks that ignore cancellation and
+// keep running after the group was abandoned are listed by name.
+func (tt *TaskTracker) ExpectNoneAbandoned(timeout time.Duration) *TaskTracker {
+	tt.x.Eventually(func() []string {
+		tt.mu.Lock()
+		defer tt.mu.Unlock()
+		return tt.runningNames()
+	}, timeout).Should(BeEmpty(), "tasks still running %s after cancellation", timeout)
+	return tt
+}
+
+// Fails the test unless the error the group returned is the first error any
+// task returned, or nil when no task failed.
+func (tt *TaskTracker) ExpectFirstErrorWins(groupErr error) *TaskTracker {
+	errs := tt.Errors()
+	if len(errs) == 0 {
+		tt.x.Expect(groupErr).ShouldNot(HaveOccurred(), "no task returned an error but the group did")
+		return tt
+	}
+	tt.x.Expect(groupErr).Should(MatchError(errs[0]), "group error was not the first task error; task errors in order %v", errs)
+	return tt
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// WriteFS is an fs.FS that also allows writing files.
+// All names are slash-separated paths relative to the file system root,
+// as for fs.FS, so code under test never sees the real temp directory path.
+type WriteFS interface {
+	fs.FS
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	MkdirAll(name string, perm fs.FileMode) error
+	Remove(name string) error
+}
+
+type dirWriteFS struct {
+	fs.FS
+	dir string
+}
+
+func (d *dirWriteFS) path(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return filepath.Join(d.dir, filepath.FromSlash(name)), nil
+}
+
+func (d *dirWriteFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	p, err := d.path("write", name)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p, data, perm)
+}
+
+func (d *dirWriteFS) MkdirAll(name string, perm fs.FileMode) error {
+	p, err := d.path("mkdir", name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(p, perm)
+}
+
+func (d *dirWriteFS) Remove(name string) error {
+	p, err := d.path("remove", name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(p)
+}
+
+// Returns a read-only fs.FS rooted at the temp directory.
+func (x *BaseTest) TempFS() fs.FS {
+	return os.DirFS(x.TempDir())
+}
+
+// Returns a writable file system rooted at the temp directory.
+func (x *BaseTest) TempWriteFS() WriteFS {
+	dir := x.TempDir()
+	return &dirWriteFS{FS: os.DirFS(dir), dir: dir}
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Tenant namespaces the names a test creates on shared infrastructure with
+// a prefix unique to the test, so parallel tests cannot collide.
+type Tenant struct {
+	x      *BaseTest
+	name   string
+	prefix string
+}
+
+var nonIdentChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+func identifier(s string, max int) string {
+	id := strings.Trim(nonIdentChars.ReplaceAllString(strings.ToLower(s), "_"), "_")
+	if len(id) > max {
+		id = id[:max]
+	}
+	return id
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// Returns the tenant with the name for this test, creating it on first use.
+func (x *BaseTest) Tenant(name string) *Tenant {
+	if x.tenants == nil {
+		x.tenants = map[string]*Tenant{}
+	}
+	if tenant, found := x.tenants[name]; found {
+		return tenant
+	}
+	prefix := strings.Join([]string{identifier(x.t.Name(), 24), identifier(name, 16), randomHex(3)}, "_")
+	tenant := &Tenant{x: x, name: name, prefix: strings.TrimPrefix(prefix, "_")}
+	x.tenants[name] = tenant
+	return tenant
+}
+
+// Returns the unique prefix, made of lowercase letters, digits and underscores.
+func (n *Tenant) Prefix() string {
+	return n.prefix
+}
+
+// Returns the identifier namespaced with the tenant prefix.
+func (n *Tenant) ID(id string) string {
+	return n.prefix + "_" + id
+}
+
+// Returns a database schema name namespaced with the tenant prefix,
+// limited to the 63 characters PostgreSQL and MySQL allow.
+func (n *Tenant) Schema(schema string) string {
+	return identifier(n.ID(schema), 63)
+}
+
+// Returns a message topic or queue name namespaced with the tenant prefix.
+func (n *Tenant) Topic(topic string) string {
+	return strings.ReplaceAll(n.prefix, "_", "-") + "." + topic
+}
+
+// Returns a temp subdirectory for the tenant, creating it on first use.
+func (n *Tenant) TempDir() string {
+	dir := n.x.TempPath(n.prefix)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		n.x.Fatalf("failed to create temp directory for tenant '%s': %s", n.name, err.Error())
+	}
+	return dir
+}
+
+// Replaces every {{tenant}} placeholder in fixture content with the tenant prefix.
+func (n *Tenant) Expand(fixture string) string {
+	return strings.ReplaceAll(fixture, "{{tenant}}", n.prefix)
+}
@@ -0,0 +1,32 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+)
+
+func allStacks() string {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// Fails the test, dumping every goroutine's stack, if it is not Done within
+// the duration.  A hung test cannot be stopped from another goroutine, so
+// like the package -timeout this ends the test binary, just much sooner.
+func (x *BaseTest) WithTimeout(d time.Duration) *BaseTest {
+	timer := time.AfterFunc(d, func() {
+		fmt.Fprintf(os.Stderr, "goonit: test %s did not finish within %s\n\n%s\n", x.t.Name(), d, allStacks())
+		panic(fmt.Sprintf("goonit: test %s timed out after %s", x.t.Name(), d))
+	})
+	x.DoAfter(func() { timer.Stop() })
+	x.t.Cleanup(func() { timer.Stop() })
+	return x
+}
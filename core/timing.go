@@ -0,0 +1,35 @@
+package core
+
+import (
+	"time"
+)
+
+// Runs priming work, such as filling caches or opening connections, and
+// excludes its duration from Elapsed so timing assertions measure steady-state
+// behavior.  Fails the test if the work takes longer than the time box.
+// Returns how long the warm-up took.
+//
+// The work runs in its own goroutine, which is left running if it times
+// out, since there is no way to stop it; CheckGoroutines reports it as a
+// leak unless the work returns by the time the test is Done.
+func (x *BaseTest) WarmUp(fn func(), timeBox time.Duration) time.Duration {
+	start := time.Now()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeBox):
+		x.Fatalf("warm-up did not finish within %s", timeBox)
+	}
+	elapsed := time.Since(start)
+	x.warmUp += elapsed
+	return elapsed
+}
+
+// Returns the time since the BaseTest was created, not counting warm-ups.
+func (x *BaseTest) Elapsed() time.Duration {
+	return time.Since(x.startedAt) - x.warmUp
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/sbernheim/goonit/httpx"
+)
+
+// Generates a self-signed certificate for the hosts, "localhost" and
+// 127.0.0.1 if none are given, valid for a day.  Writes the certificate and
+// key as PEM files in the temp dir and returns their paths.  Each call
+// writes a new pair of files.
+func (x *BaseTest) SelfSignedCert(hosts ...string) (certFile, keyFile string) {
+	if len(hosts) == 0 {
+		hosts = []string{"localhost", "127.0.0.1", "::1"}
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		x.Fatalf("failed to generate certificate key: %s", err.Error())
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		x.Fatalf("failed to generate certificate serial number: %s", err.Error())
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"goonit"}, CommonName: hosts[0]},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		x.Fatalf("failed to create certificate for %v: %s", hosts, err.Error())
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		x.Fatalf("failed to encode certificate key: %s", err.Error())
+	}
+	x.certs++
+	certFile = x.TempPath(fmt.Sprintf("cert%d.pem", x.certs))
+	keyFile = x.TempPath(fmt.Sprintf("key%d.pem", x.certs))
+	x.writePEM(certFile, "CERTIFICATE", der)
+	x.writePEM(keyFile, "PRIVATE KEY", keyDER)
+	return certFile, keyFile
+}
+
+func (x *BaseTest) writePEM(path, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		x.Fatalf("failed to write '%s': %s", path, err.Error())
+	}
+}
+
+// Starts an HTTPS server replying from stubbed routes with a certificate
+// from SelfSignedCert, and returns it with a client that trusts only that
+// certificate.  The server is shut down when the test is Done.
+func (x *BaseTest) HTTPSServer() (*httpx.Server, *http.Client) {
+	certFile, keyFile := x.SelfSignedCert()
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		x.Fatalf("failed to load certificate '%s': %s", certFile, err.Error())
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		x.Fatalf("failed to parse certificate '%s': %s", certFile, err.Error())
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	s := httpx.NewTLSServer(x.t, &tls.Config{Certificates: []tls.Certificate{cert}})
+	x.DebugInfo("HTTPS server", s.URL)
+	x.DoAfter(s.Close)
+	transport := &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}
+	x.DoAfter(transport.CloseIdleConnections)
+	return s, &http.Client{Transport: transport}
+}
@@ -0,0 +1,26 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Runs fn inside a transaction on the database and rolls it back when the
+// test is Done, so integration tests against a shared database leave
+// nothing behind, even if fn fails the test.  Fails the test if the
+// transaction was committed, since its changes can no longer be undone.
+func (x *BaseTest) InTransaction(db *sql.DB, fn func(tx *sql.Tx)) {
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		x.Fatalf("failed to begin transaction: %s", err.Error())
+	}
+	x.DoAfter(func() {
+		if err := tx.Rollback(); errors.Is(err, sql.ErrTxDone) {
+			x.t.Errorf("transaction started by InTransaction was already committed or rolled back; committed changes stay in the database")
+		} else if err != nil {
+			x.t.Errorf("failed to roll back transaction: %s", err.Error())
+		}
+	})
+	fn(tx)
+}
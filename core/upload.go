@@ -0,0 +1,78 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Artifact is a file a test saved in its artifact directory.
+type Artifact struct {
+	Test string
+	Name string
+	Path string
+}
+
+// Uploader pushes artifacts to storage such as S3, GCS or a CI service's
+// artifact store when the test is Done.
+type Uploader interface {
+	Upload(artifact Artifact) error
+}
+
+// UploaderFunc adapts a function to the Uploader interface.
+type UploaderFunc func(artifact Artifact) error
+
+func (f UploaderFunc) Upload(artifact Artifact) error {
+	return f(artifact)
+}
+
+var (
+	uploadersMu sync.Mutex
+	uploaders   []Uploader
+)
+
+// Registers an uploader for the artifacts of every test in the process.
+func RegisterUploader(u Uploader) {
+	uploadersMu.Lock()
+	defer uploadersMu.Unlock()
+	uploaders = append(uploaders, u)
+}
+
+// Adds an uploader for the artifacts of this BaseTest only.
+func WithUploader(u Uploader) Option {
+	return func(o *options) {
+		o.uploaders = append(o.uploaders, u)
+	}
+}
+
+func (x *BaseTest) allUploaders() []Uploader {
+	uploadersMu.Lock()
+	defer uploadersMu.Unlock()
+	return append(append([]Uploader{}, uploaders...), x.uploaders...)
+}
+
+// Passes every file in the artifact directory to every uploader.
+// Upload failures fail the test without stopping the other uploads.
+func (x *BaseTest) uploadArtifacts() {
+	if !x.usedArtifacts {
+		return
+	}
+	all := x.allUploaders()
+	if len(all) == 0 {
+		return
+	}
+	dir := x.artifactDir()
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		name, _ := filepath.Rel(dir, path)
+		artifact := Artifact{Test: x.t.Name(), Name: filepath.ToSlash(name), Path: path}
+		for _, u := range all {
+			if err := u.Upload(artifact); err != nil {
+				x.t.Errorf("failed to upload artifact '%s': %s", artifact.Name, err.Error())
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/golang/mock/gomock"
+)
+
+// MockCall is a call to a mock recorded for Verify.
+type MockCall struct {
+	Mock     string
+	Method   string
+	Args     []interface{}
+	receiver uintptr
+}
+
+func (c MockCall) String() string {
+	args := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		args[i] = fmt.Sprintf("%#v", arg)
+	}
+	return fmt.Sprintf("%s.%s(%s)", c.Mock, c.Method, strings.Join(args, ", "))
+}
+
+func (x *BaseTest) recordMockCall(receiver uintptr, mock, method string, args []interface{}) {
+	x.mockCallsMu.Lock()
+	defer x.mockCallsMu.Unlock()
+	x.mockCalls = append(x.mockCalls, MockCall{Mock: mock, Method: method, Args: args, receiver: receiver})
+}
+
+// Returns every recorded call to any mock, in order.
+func (x *BaseTest) MockCalls() []MockCall {
+	x.mockCallsMu.Lock()
+	defer x.mockCallsMu.Unlock()
+	return append([]MockCall{}, x.mockCalls...)
+}
+
+// Verification checks the calls recorded for a mock after the code under
+// test ran.
+type Verification struct {
+	x     *BaseTest
+	calls []MockCall
+	name  string
+}
+
+// Returns a verification of the calls the mock recorded.  Mocks record the
+// calls they relax with Relax and the calls stubbed with Stub, so
+// Mockito-style tests relax a mock, run the code, then verify:
+//
+//	x.Relax(repo)
+//	service.Save(user)
+//	x.Verify(repo).Call("Put", user.ID, match.AnyString()).Times(1)
+func (x *BaseTest) Verify(m interface{}) *Verification {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Ptr {
+		x.Fatalf("Verify needs a generated mock, passed %T", m)
+	}
+	verification := &Verification{x: x, name: reflect.Indirect(v).Type().Name()}
+	for _, call := range x.MockCalls() {
+		if call.receiver == v.Pointer() {
+			verification.calls = append(verification.calls, call)
+		}
+	}
+	return verification
+}
+
+// Returns the mock's recorded calls, in order.
+func (v *Verification) Calls() []MockCall {
+	return v.calls
+}
+
+// Selects the calls to the method whose arguments match: each is a
+// gomock.Matcher or a value compared with gomock.Eq.  Variadic arguments
+// are matched one by one.
+func (v *Verification) Call(method string, args ...interface{}) *CallVerification {
+	matchers := make([]gomock.Matcher, len(args))
+	for i, arg := range args {
+		if m, ok := arg.(gomock.Matcher); ok {
+			matchers[i] = m
+		} else {
+			matchers[i] = gomock.Eq(arg)
+		}
+	}
+	cv := &CallVerification{v: v, method: method, matchers: matchers}
+	for _, call := range v.calls {
+		if call.Method == method && argsMatch(matchers, call.Args) {
+			cv.matched++
+		}
+	}
+	return cv
+}
+
+// Fails the test if the mock recorded any calls.
+func (v *Verification) NoCalls() {
+	if len(v.calls) > 0 {
+		v.x.Fatalf("expected no calls to %s\n%s", v.name, v.recorded())
+	}
+}
+
+func (v *Verification) recorded() string {
+	if len(v.calls) == 0 {
+		return "no calls were recorded"
+	}
+	lines := make([]string, len(v.calls))
+	for i, call := range v.calls {
+		lines[i] = "  " + call.String()
+	}
+	return "recorded calls:\n" + strings.Join(lines, "\n")
+}
+
+func argsMatch(matchers []gomock.Matcher, args []interface{}) bool {
+	if len(matchers) != len(args) {
+		return false
+	}
+	for i, m := range matchers {
+		if !m.Matches(args[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// CallVerification checks how many times a mock's recorded calls matched.
+type CallVerification struct {
+	v        *Verification
+	method   string
+	matchers []gomock.Matcher
+	matched  int
+}
+
+func (cv *CallVerification) String() string {
+	args := make([]string, len(cv.matchers))
+	for i, m := range cv.matchers {
+		args[i] = m.String()
+	}
+	return fmt.Sprintf("%s.%s(%s)", cv.v.name, cv.method, strings.Join(args, ", "))
+}
+
+func (cv *CallVerification) check(ok bool, want string) *CallVerification {
+	if !ok {
+		cv.v.x.Fatalf("expected %s %s but it was called %d times\n%s", cv, want, cv.matched, cv.v.recorded())
+	}
+	return cv
+}
+
+func (cv *CallVerification) Times(n int) *CallVerification {
+	return cv.check(cv.matched == n, fmt.Sprintf("to be called %d times", n))
+}
+
+func (cv *CallVerification) Once() *CallVerification {
+	return cv.Times(1)
+}
+
+func (cv *CallVerification) Never() *CallVerification {
+	return cv.check(cv.matched == 0, "never to be called")
+}
+
+func (cv *CallVerification) AtLeast(n int) *CallVerification {
+	return cv.check(cv.matched >= n, fmt.Sprintf("to be called at least %d times", n))
+}
+
+func (cv *CallVerification) AtMost(n int) *CallVerification {
+	return cv.check(cv.matched <= n, fmt.Sprintf("to be called at most %d times", n))
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// VersionReporter is an integration target client that reports the version
+// of the server it is connected to.
+type VersionReporter interface {
+	ServerVersion() (string, error)
+}
+
+// VersionFunc adapts a function, such as one that runs `SELECT version()`,
+// to the VersionReporter interface.
+type VersionFunc func() (string, error)
+
+func (f VersionFunc) ServerVersion() (string, error) {
+	return f()
+}
+
+var versionPattern = regexp.MustCompile(`\d+(\.\d+)*`)
+
+// Returns the numeric parts of the first dotted version in s, so reports
+// like "PostgreSQL 13.4 on x86_64" and "v1.21.3" both parse.
+func parseVersion(s string) ([]int, error) {
+	match := versionPattern.FindString(s)
+	if match == "" {
+		return nil, fmt.Errorf("no version number in '%s'", s)
+	}
+	parts := []int{}
+	for _, p := range strings.Split(match, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, n)
+	}
+	return parts, nil
+}
+
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var av, bv int
+		if i < len(a) {
+			av = a[i]
+		}
+		if i < len(b) {
+			bv = b[i]
+		}
+		if av != bv {
+			if av < bv {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+var constraintPattern = regexp.MustCompile(`^(>=|<=|!=|==|=|>|<|~|\^)?\s*v?(\d+(\.\d+)*)$`)
+
+// Returns whether the version satisfies every comma separated clause of the
+// constraint, e.g. ">=13, <15".  ~1.2 allows any 1.2.x and ^1.2 any 1.x from 1.2.
+func satisfies(version []int, constraint string) (bool, error) {
+	for _, clause := range strings.Split(constraint, ",") {
+		clause = strings.TrimSpace(clause)
+		m := constraintPattern.FindStringSubmatch(clause)
+		if m == nil {
+			return false, fmt.Errorf("invalid version constraint '%s'", clause)
+		}
+		want, _ := parseVersion(m[2])
+		cmp := compareVersions(version, want)
+		var ok bool
+		switch m[1] {
+		case "", "=", "==":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		case "~":
+			ok = cmp >= 0 && compareVersions(version[:minInt(len(version), 2)], want[:minInt(len(want), 2)]) == 0
+		case "^":
+			ok = cmp >= 0 && version[0] == want[0]
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func (x *BaseTest) checkServerVersion(client VersionReporter, constraint string) (string, bool) {
+	reported, err := client.ServerVersion()
+	if err != nil {
+		x.Fatalf("failed to get the server version: %s", err.Error())
+	}
+	version, err := parseVersion(reported)
+	if err != nil {
+		x.Fatalf("failed to parse the server version: %s", err.Error())
+	}
+	ok, err := satisfies(version, constraint)
+	if err != nil {
+		x.Fatalf("%s", err.Error())
+	}
+	return reported, ok
+}
+
+// Fails the test unless the version the server reports satisfies the
+// constraint the test was written against.
+func (x *BaseTest) ExpectServerVersion(client VersionReporter, constraint string) {
+	if reported, ok := x.checkServerVersion(client, constraint); !ok {
+		x.Fatalf("server version '%s' does not satisfy the tested contract '%s'", reported, constraint)
+	}
+}
+
+// Skips the test unless the version the server reports satisfies the constraint.
+func (x *BaseTest) SkipUnlessServerVersion(client VersionReporter, constraint string) {
+	if reported, ok := x.checkServerVersion(client, constraint); !ok {
+		x.t.Skipf("server version '%s' does not satisfy '%s'", reported, constraint)
+	}
+}
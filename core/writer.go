@@ -0,0 +1,131 @@
+package core
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// RecordingWriter is an io.WriteCloser that keeps everything written to it
+// and each separate Write, for assertions on how code under test writes.
+type RecordingWriter struct {
+	x      *BaseTest
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	writes [][]byte
+	closes int
+	err    error
+	afterN int
+}
+
+// Returns a writer that records writes and never fails.
+func (x *BaseTest) RecordingWriter() *RecordingWriter {
+	return &RecordingWriter{x: x, afterN: -1}
+}
+
+// Returns a writer that accepts afterN bytes and then fails with err,
+// reporting a partial write for the Write that crosses the limit.
+func (x *BaseTest) FailingWriter(err error, afterN int) *RecordingWriter {
+	return &RecordingWriter{x: x, err: err, afterN: afterN}
+}
+
+func (w *RecordingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n := len(p)
+	var err error
+	if w.afterN >= 0 && w.buf.Len()+n > w.afterN {
+		n = w.afterN - w.buf.Len()
+		err = w.err
+	}
+	w.writes = append(w.writes, append([]byte{}, p[:n]...))
+	w.buf.Write(p[:n])
+	return n, err
+}
+
+// Counts the call; closing more than once is left for ExpectClosed to report.
+func (w *RecordingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closes++
+	return nil
+}
+
+// Returns everything written so far.
+func (w *RecordingWriter) Bytes() []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]byte{}, w.buf.Bytes()...)
+}
+
+func (w *RecordingWriter) String() string {
+	return string(w.Bytes())
+}
+
+// Returns the bytes accepted by each Write call, in order.
+func (w *RecordingWriter) Writes() [][]byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([][]byte{}, w.writes...)
+}
+
+// Fails the test unless exactly the expected content was written.
+func (w *RecordingWriter) ExpectWritten(expected string) *RecordingWriter {
+	if actual := w.String(); actual != expected {
+		w.x.Fatalf("expected writer to have %q written to it but got %q", expected, actual)
+	}
+	return w
+}
+
+// Fails the test unless the content was written in exactly n Write calls.
+func (w *RecordingWriter) ExpectWrites(n int) *RecordingWriter {
+	if actual := len(w.Writes()); actual != n {
+		w.x.Fatalf("expected %d writes but got %d", n, actual)
+	}
+	return w
+}
+
+// Fails the test unless the writer was closed exactly once.
+func (w *RecordingWriter) ExpectClosed() *RecordingWriter {
+	w.mu.Lock()
+	closes := w.closes
+	w.mu.Unlock()
+	if closes != 1 {
+		w.x.Fatalf("expected writer to be closed once but it was closed %d times", closes)
+	}
+	return w
+}
+
+// TrackedCloser counts calls to Close before passing them to the closer
+// it wraps.
+type TrackedCloser struct {
+	io.Closer
+	mu     sync.Mutex
+	closes int
+}
+
+func (c *TrackedCloser) Close() error {
+	c.mu.Lock()
+	c.closes++
+	c.mu.Unlock()
+	return c.Closer.Close()
+}
+
+// Returns how many times Close has been called.
+func (c *TrackedCloser) Closes() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closes
+}
+
+// Wraps the closer and fails the test when it is Done unless the wrapper
+// was closed exactly once.  Pass the returned closer to the code under test.
+func (x *BaseTest) AssertClosed(closer io.Closer) *TrackedCloser {
+	c := &TrackedCloser{Closer: closer}
+	x.DoAfter(func() {
+		if closes := c.Closes(); closes != 1 {
+			x.Fatalf("expected %T to be closed once but it was closed %d times", closer, closes)
+		}
+	})
+	return c
+}
@@ -0,0 +1,144 @@
+// Package dbmock mocks database/sql for tests with go-sqlmock, adding
+// helpers for the expectations SQL-layer tests set most often:
+//
+//	db, dbm := dbmock.Open(x)
+//	dbm.ExpectQueryRows("SELECT name FROM users", []string{"name"}, []driver.Value{"ann"})
+//	dbm.ExpectTx(func() {
+//		dbm.ExpectExecResult("UPDATE users", 0, 1)
+//	})
+//
+// Queries are matched as sqlmock matches them by default, by regular
+// expression, unless the mock is opened with MatchQueries.  See:
+// https://github.com/DATA-DOG/go-sqlmock
+//
+// It is a module of its own, so only tests that use it depend on go-sqlmock.
+package dbmock
+
+import (
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/sbernheim/goonit/core"
+)
+
+// T is the part of *testing.T the mock reports failures through.
+type T interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// Mock is a sqlmock.Sqlmock with helpers for common expectations.
+type Mock struct {
+	sqlmock.Sqlmock
+	t T
+}
+
+// Option configures a mock database.
+type Option func(o *options)
+
+type options struct {
+	matcher sqlmock.QueryMatcher
+	pings   bool
+}
+
+// Matches expected queries with the matcher, such as
+// sqlmock.QueryMatcherEqual, in place of regular expressions.
+func MatchQueries(matcher sqlmock.QueryMatcher) Option {
+	return func(o *options) {
+		o.matcher = matcher
+	}
+}
+
+// Makes pinging the database an expectation, set with ExpectPing, in place
+// of always succeeding.
+func MonitorPings() Option {
+	return func(o *options) {
+		o.pings = true
+	}
+}
+
+// Opens a *sql.DB backed by a new sqlmock.  Close the DB and then call
+// Verify when the test is done, so an ExpectClose is met.
+func New(t T, opts ...Option) (*sql.DB, *Mock) {
+	t.Helper()
+	o := &options{matcher: sqlmock.QueryMatcherRegexp}
+	for _, opt := range opts {
+		opt(o)
+	}
+	db, m, err := sqlmock.New(sqlmock.QueryMatcherOption(o.matcher), sqlmock.MonitorPingsOption(o.pings))
+	if err != nil {
+		t.Fatalf("failed to open mock database: %s", err.Error())
+	}
+	return db, &Mock{Sqlmock: m, t: t}
+}
+
+// Opens a *sql.DB backed by go-sqlmock and returns it with the mock to set
+// expectations on.  When the test is Done the DB is closed, meeting any
+// ExpectClose, and the mock fails the test if an expectation was not met.
+func Open(x *core.BaseTest, opts ...Option) (*sql.DB, *Mock) {
+	db, m := New(x.TestingT(), opts...)
+	x.DoAfter(func() {
+		// Without an ExpectClose sqlmock fails the close, which is fine.
+		db.Close()
+		m.Verify()
+	})
+	return db, m
+}
+
+// Returns rows with the columns and values, for WillReturnRows.
+func Rows(columns []string, values ...[]driver.Value) *sqlmock.Rows {
+	rows := sqlmock.NewRows(columns)
+	for _, v := range values {
+		rows.AddRow(v...)
+	}
+	return rows
+}
+
+// Expects the query and replies with rows with the columns and values.
+// Narrow it to arguments with WithArgs.
+func (m *Mock) ExpectQueryRows(query string, columns []string, values ...[]driver.Value) *sqlmock.ExpectedQuery {
+	return m.ExpectQuery(query).WillReturnRows(Rows(columns, values...))
+}
+
+// Expects the query and fails it with err.
+func (m *Mock) ExpectQueryError(query string, err error) *sqlmock.ExpectedQuery {
+	return m.ExpectQuery(query).WillReturnError(err)
+}
+
+// Expects the statement and replies with a result with the insert ID and
+// the number of rows affected.
+func (m *Mock) ExpectExecResult(query string, lastInsertID, rowsAffected int64) *sqlmock.ExpectedExec {
+	return m.ExpectExec(query).WillReturnResult(sqlmock.NewResult(lastInsertID, rowsAffected))
+}
+
+// Expects the statement and fails it with err.
+func (m *Mock) ExpectExecError(query string, err error) *sqlmock.ExpectedExec {
+	return m.ExpectExec(query).WillReturnError(err)
+}
+
+// Expects a transaction to begin, then the expectations expect sets, then
+// the transaction to commit.
+func (m *Mock) ExpectTx(expect func()) *sqlmock.ExpectedCommit {
+	m.ExpectBegin()
+	expect()
+	return m.ExpectCommit()
+}
+
+// Expects a transaction to begin, then the expectations expect sets, then
+// the transaction to roll back.
+func (m *Mock) ExpectRolledBackTx(expect func()) *sqlmock.ExpectedRollback {
+	m.ExpectBegin()
+	expect()
+	return m.ExpectRollback()
+}
+
+// Fails the test if an expectation was not met.
+func (m *Mock) Verify() {
+	m.t.Helper()
+	if err := m.ExpectationsWereMet(); err != nil {
+		m.t.Errorf("database mock expectations were not met: %s", err.Error())
+	}
+}
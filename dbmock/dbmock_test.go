@@ -0,0 +1,24 @@
+package dbmock
+
+import (
+	"testing"
+
+	"github.com/sbernheim/goonit/core"
+)
+
+func TestOpenClosesBeforeVerifying(t *testing.T) {
+	x := core.New(t, core.WithoutMocks())
+	db, dbm := Open(x)
+	dbm.ExpectExecResult("DELETE FROM users", 0, 1)
+	dbm.ExpectClose()
+	if _, err := db.Exec("DELETE FROM users"); err != nil {
+		t.Fatal(err)
+	}
+	x.Done()
+}
+
+func TestOpenClosesWithoutExpectClose(t *testing.T) {
+	x := core.New(t, core.WithoutMocks())
+	Open(x)
+	x.Done()
+}
@@ -0,0 +1,22 @@
+module github.com/sbernheim/goonit/dbmock
+
+go 1.18
+
+require (
+	github.com/DATA-DOG/go-sqlmock v1.5.0
+	github.com/sbernheim/goonit v0.0.0
+)
+
+require (
+	github.com/go-logr/logr v0.4.0 // indirect
+	github.com/golang/mock v1.6.0 // indirect
+	github.com/google/go-cmp v0.5.6 // indirect
+	github.com/onsi/gomega v1.16.0 // indirect
+	golang.org/x/net v0.0.0-20210428140749-89ef3d95e781 // indirect
+	golang.org/x/text v0.3.6 // indirect
+	google.golang.org/protobuf v1.27.1 // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+)
+
+// Builds against the goonit in this repository.
+replace github.com/sbernheim/goonit => ../
@@ -0,0 +1,17 @@
+package diff
+
+import (
+	"github.com/google/go-cmp/cmp"
+)
+
+// Returns a diff that renders go-cmp's field level report, labelled
+// -expected +actual, comparing with the options.
+func Cmp(opts ...cmp.Option) Diff {
+	return DiffFunc(func(expected, actual interface{}) string {
+		d := cmp.Diff(expected, actual, opts...)
+		if d == "" {
+			return ""
+		}
+		return "(-expected +actual):\n" + d
+	})
+}
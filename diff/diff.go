@@ -0,0 +1,137 @@
+// Package diff renders the differences goonit reports when a comparison
+// fails, so a suite can show every failed golden file, equality assertion
+// and directory comparison the same way.
+//
+// Built in are Unified text diffs, JSONPatch documents and go-cmp's Cmp
+// output.  Register others by name and make one the default for the whole
+// suite with SetDefault, typically from TestMain.
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Diff describes how actual differs from expected, returning "" when it
+// finds no difference.
+type Diff interface {
+	Diff(expected, actual interface{}) string
+}
+
+// DiffFunc adapts a function to the Diff interface.
+type DiffFunc func(expected, actual interface{}) string
+
+func (f DiffFunc) Diff(expected, actual interface{}) string {
+	return f(expected, actual)
+}
+
+var (
+	registryMu  sync.Mutex
+	registry    = map[string]Diff{}
+	defaultDiff Diff
+)
+
+func init() {
+	Register("unified", Unified)
+	Register("jsonpatch", JSONPatch)
+	Register("cmp", Cmp())
+}
+
+// Registers the diff under the name, replacing any registered before.
+func Register(name string, d Diff) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = d
+}
+
+// Returns the diff registered under the name.
+func Get(name string) (Diff, bool) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	d, found := registry[name]
+	return d, found
+}
+
+// Returns the names of the registered diffs, sorted.
+func Names() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Makes the diff the one every comparison uses, in place of the one each
+// kind of comparison picks by default.  Pass nil to restore those.
+func SetDefault(d Diff) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	defaultDiff = d
+}
+
+// Makes the diff registered under the name the default.
+func SetDefaultName(name string) error {
+	d, found := Get(name)
+	if !found {
+		return fmt.Errorf("no diff registered as '%s'; registered are %v", name, Names())
+	}
+	SetDefault(d)
+	return nil
+}
+
+// Returns the default diff, or fallback if none has been set.
+func Default(fallback Diff) Diff {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if defaultDiff != nil {
+		return defaultDiff
+	}
+	return fallback
+}
+
+// Returns the value as text: strings and byte slices as is, everything
+// else as indented JSON, or in Go syntax if it cannot be encoded.
+func text(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case fmt.Stringer:
+		return val.String()
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%#v", v)
+	}
+	return string(data)
+}
+
+// Decodes JSON text, or round trips any other value through JSON.
+func jsonValue(v interface{}) (interface{}, error) {
+	var data []byte
+	switch val := v.(type) {
+	case string:
+		data = []byte(val)
+	case []byte:
+		data = val
+	case json.RawMessage:
+		data = val
+	default:
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var decoded interface{}
+	err := dec.Decode(&decoded)
+	return decoded, err
+}
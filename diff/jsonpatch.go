@@ -0,0 +1,99 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// JSONPatch renders the RFC 6902 JSON Patch that turns expected into
+// actual, one operation per line.  Values that are not JSON text are
+// compared as their JSON encoding.
+var JSONPatch Diff = DiffFunc(jsonPatch)
+
+type patchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+func jsonPatch(expected, actual interface{}) string {
+	e, err := jsonValue(expected)
+	if err != nil {
+		return Unified.Diff(expected, actual)
+	}
+	a, err := jsonValue(actual)
+	if err != nil {
+		return Unified.Diff(expected, actual)
+	}
+	ops := patchOps("", e, a, nil)
+	if len(ops) == 0 {
+		return ""
+	}
+	b := &strings.Builder{}
+	b.WriteString("[\n")
+	for i, op := range ops {
+		data, _ := json.Marshal(op)
+		b.WriteString("  ")
+		b.Write(data)
+		if i < len(ops)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("]\n")
+	return b.String()
+}
+
+func pointerToken(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
+
+func patchOps(path string, e, a interface{}, ops []patchOp) []patchOp {
+	switch ev := e.(type) {
+	case map[string]interface{}:
+		av, ok := a.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(ev)+len(av))
+		for key := range ev {
+			keys = append(keys, key)
+		}
+		for key := range av {
+			if _, found := ev[key]; !found {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			p := path + "/" + pointerToken(key)
+			eItem, inE := ev[key]
+			aItem, inA := av[key]
+			switch {
+			case !inA:
+				ops = append(ops, patchOp{Op: "remove", Path: p})
+			case !inE:
+				ops = append(ops, patchOp{Op: "add", Path: p, Value: aItem})
+			default:
+				ops = patchOps(p, eItem, aItem, ops)
+			}
+		}
+		return ops
+	case []interface{}:
+		av, ok := a.([]interface{})
+		if !ok || len(av) != len(ev) {
+			break
+		}
+		for i := range ev {
+			ops = patchOps(fmt.Sprintf("%s/%d", path, i), ev[i], av[i], ops)
+		}
+		return ops
+	}
+	if reflect.DeepEqual(e, a) {
+		return ops
+	}
+	return append(ops, patchOp{Op: "replace", Path: path, Value: a})
+}
@@ -0,0 +1,145 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Lines of unchanged text shown around each change.
+const contextLines = 3
+
+// Inputs with more lines than this squared are shown whole rather than
+// diffed line by line.
+const maxLines = 2000
+
+// Unified renders a unified diff of the values as text, labelled
+// expected and actual.
+var Unified Diff = DiffFunc(unified)
+
+type edit struct {
+	op   byte
+	line string
+}
+
+func unified(expected, actual interface{}) string {
+	e, a := text(expected), text(actual)
+	if e == a {
+		return ""
+	}
+	el, al := splitLines(e), splitLines(a)
+	b := &strings.Builder{}
+	b.WriteString("--- expected\n+++ actual\n")
+	if len(el) > maxLines || len(al) > maxLines {
+		for _, line := range el {
+			fmt.Fprintf(b, "-%s\n", line)
+		}
+		for _, line := range al {
+			fmt.Fprintf(b, "+%s\n", line)
+		}
+		return b.String()
+	}
+	writeHunks(b, lineEdits(el, al))
+	return b.String()
+}
+
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for i, line := range lines {
+		if strings.HasSuffix(line, "\n") {
+			lines[i] = strings.TrimSuffix(line, "\n")
+		} else {
+			lines[i] = line + "\n\\ No newline at end of text"
+		}
+	}
+	return lines
+}
+
+// Returns the edits turning e into a, from their longest common subsequence.
+func lineEdits(e, a []string) []edit {
+	lcs := make([][]int, len(e)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(a)+1)
+	}
+	for i := len(e) - 1; i >= 0; i-- {
+		for j := len(a) - 1; j >= 0; j-- {
+			if e[i] == a[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	edits := []edit{}
+	i, j := 0, 0
+	for i < len(e) || j < len(a) {
+		switch {
+		case i < len(e) && j < len(a) && e[i] == a[j]:
+			edits = append(edits, edit{' ', e[i]})
+			i++
+			j++
+		case j == len(a) || (i < len(e) && lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, edit{'-', e[i]})
+			i++
+		default:
+			edits = append(edits, edit{'+', a[j]})
+			j++
+		}
+	}
+	return edits
+}
+
+func writeHunks(b *strings.Builder, edits []edit) {
+	for start := 0; start < len(edits); {
+		for start < len(edits) && edits[start].op == ' ' {
+			start++
+		}
+		if start == len(edits) {
+			return
+		}
+		from := start - contextLines
+		if from < 0 {
+			from = 0
+		}
+		// Extend the hunk while changes are close enough to share context.
+		to, unchanged := start, 0
+		for to < len(edits) && unchanged <= 2*contextLines {
+			if edits[to].op == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+			to++
+		}
+		if unchanged > contextLines {
+			to -= unchanged - contextLines
+		}
+		eStart, aStart := 1, 1
+		for _, ed := range edits[:from] {
+			if ed.op != '+' {
+				eStart++
+			}
+			if ed.op != '-' {
+				aStart++
+			}
+		}
+		eCount, aCount := 0, 0
+		for _, ed := range edits[from:to] {
+			if ed.op != '+' {
+				eCount++
+			}
+			if ed.op != '-' {
+				aCount++
+			}
+		}
+		fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", eStart, eCount, aStart, aCount)
+		for _, ed := range edits[from:to] {
+			fmt.Fprintf(b, "%c%s\n", ed.op, ed.line)
+		}
+		start = to
+	}
+}
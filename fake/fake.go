@@ -0,0 +1,94 @@
+// Package fake generates realistic looking test data.  A Faker created with
+// the same seed always generates the same values in the same order.
+package fake
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+var (
+	firstNames = []string{"Ada", "Alan", "Barbara", "Claude", "Dennis", "Edsger", "Frances", "Grace", "Hedy", "Ken", "Linus", "Margaret", "Niklaus", "Radia", "Rob", "Sophie"}
+	lastNames  = []string{"Allen", "Hamilton", "Hopper", "Kernighan", "Knuth", "Lamarr", "Liskov", "Lovelace", "Perlman", "Pike", "Ritchie", "Shannon", "Thompson", "Torvalds", "Turing", "Wirth"}
+	streets    = []string{"Main St", "Oak Ave", "Maple Dr", "Cedar Ln", "Elm St", "Pine Rd", "Lake View Blvd", "Hill Ct"}
+	cities     = []string{"Springfield", "Riverside", "Fairview", "Greenville", "Madison", "Georgetown", "Salem", "Franklin"}
+	states     = []string{"CA", "CO", "IL", "MA", "NY", "OR", "TX", "WA"}
+	domains    = []string{"example.com", "example.net", "example.org"}
+	words      = []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel", "india", "juliet"}
+)
+
+type Faker struct {
+	r *rand.Rand
+}
+
+// Returns a Faker that generates values from the seed.
+func New(seed int64) *Faker {
+	return NewFromRand(rand.New(rand.NewSource(seed)))
+}
+
+// Returns a Faker that generates values from the random source.
+func NewFromRand(r *rand.Rand) *Faker {
+	return &Faker{r: r}
+}
+
+func (f *Faker) pick(values []string) string {
+	return values[f.r.Intn(len(values))]
+}
+
+func (f *Faker) FirstName() string {
+	return f.pick(firstNames)
+}
+
+func (f *Faker) LastName() string {
+	return f.pick(lastNames)
+}
+
+func (f *Faker) Name() string {
+	return f.FirstName() + " " + f.LastName()
+}
+
+// Returns an email address at one of the reserved example domains.
+func (f *Faker) Email() string {
+	return fmt.Sprintf("%s.%s%d@%s", strings.ToLower(f.FirstName()), strings.ToLower(f.LastName()), f.r.Intn(100), f.pick(domains))
+}
+
+func (f *Faker) Address() string {
+	return fmt.Sprintf("%d %s, %s, %s %05d", 1+f.r.Intn(9999), f.pick(streets), f.pick(cities), f.pick(states), f.r.Intn(100000))
+}
+
+// Returns a phone number in the 555-01xx range reserved for fiction.
+func (f *Faker) Phone() string {
+	return fmt.Sprintf("(%03d) 555-01%02d", 200+f.r.Intn(800), f.r.Intn(100))
+}
+
+// Returns a random version 4 UUID.
+func (f *Faker) UUID() string {
+	b := make([]byte, 16)
+	f.r.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// Returns an https URL at one of the reserved example domains.
+func (f *Faker) URL() string {
+	return fmt.Sprintf("https://%s.%s/%s/%d", f.pick(words), f.pick(domains), f.pick(words), f.r.Intn(1000))
+}
+
+// Returns a UTC time, truncated to the second, within ten years of the start of 2020.
+func (f *Faker) Timestamp() time.Time {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	span := int64(10 * 365 * 24 * time.Hour / time.Second)
+	return base.Add(time.Duration(f.r.Int63n(2*span)-span) * time.Second)
+}
+
+// Returns a Timestamp between from and to.
+func (f *Faker) TimestampBetween(from, to time.Time) time.Time {
+	span := to.Sub(from)
+	if span <= 0 {
+		return from
+	}
+	return from.Add(time.Duration(f.r.Int63n(int64(span))))
+}
@@ -0,0 +1,34 @@
+// Package goonitginkgo lets Ginkgo specs use goonit's capture, env, temp and
+// mock helpers.  Failures from BaseTest, its Gomega assertions and its gomock
+// controller all go through GinkgoT() to Ginkgo's fail handler.
+//
+//	var x *core.BaseTest
+//
+//	BeforeEach(func() {
+//		x = goonitginkgo.New(GinkgoT())
+//	})
+//
+//	AfterEach(func() {
+//		x.Done()
+//	})
+package goonitginkgo
+
+import (
+	"github.com/onsi/ginkgo"
+
+	"github.com/sbernheim/goonit/core"
+)
+
+// Creates a BaseTest for the current spec.  Call Done in an AfterEach.
+func New(t ginkgo.GinkgoTInterface, opts ...core.Option) *core.BaseTest {
+	return core.NewT(specT{t}, opts...)
+}
+
+// specT is GinkgoT() without its Setenv, which only prints that it does
+// nothing.  Ginkgo runs a process's specs one at a time, so there is no
+// parallel test for BaseTest's SetEnv and SetArgs to guard against.
+type specT struct {
+	ginkgo.GinkgoTInterface
+}
+
+func (t specT) Setenv(key, value string) {}
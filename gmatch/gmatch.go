@@ -0,0 +1,77 @@
+// Package gmatch provides Gomega matchers with the same semantics as the
+// gomock argument matchers in the match package, so the checks made with
+// EXPECT() and with Expect() agree.
+//
+//	x.Expect(body).Should(gmatch.JSONEq(`{"id": 7}`))
+//	mockStore.EXPECT().Put(match.JSONEq(`{"id": 7}`))
+package gmatch
+
+import (
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/onsi/gomega/format"
+	"github.com/onsi/gomega/types"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sbernheim/goonit/match"
+)
+
+type gomockMatcher struct{ m gomock.Matcher }
+
+// Adapts any gomock matcher for use with Gomega's Expect.
+func FromGomock(m gomock.Matcher) types.GomegaMatcher {
+	return &gomockMatcher{m}
+}
+
+func (g *gomockMatcher) Match(actual interface{}) (bool, error) {
+	return g.m.Matches(actual), nil
+}
+
+func (g *gomockMatcher) FailureMessage(actual interface{}) string {
+	return format.Message(actual, "to match: "+g.m.String())
+}
+
+func (g *gomockMatcher) NegatedFailureMessage(actual interface{}) string {
+	return format.Message(actual, "not to match: "+g.m.String())
+}
+
+func IsType(t interface{}) types.GomegaMatcher {
+	return FromGomock(match.IsType(t))
+}
+
+func AnyString() types.GomegaMatcher {
+	return FromGomock(match.AnyString())
+}
+
+func AnyFunc() types.GomegaMatcher {
+	return FromGomock(match.AnyFunc())
+}
+
+func HashOfContent(alg, hexDigest string) types.GomegaMatcher {
+	return FromGomock(match.HashOfContent(alg, hexDigest))
+}
+
+func JSONEq(expected string) types.GomegaMatcher {
+	return FromGomock(match.JSONEq(expected))
+}
+
+func ProtoEqual(expected proto.Message) types.GomegaMatcher {
+	return FromGomock(match.ProtoEqual(expected))
+}
+
+func ErrorIs(target error) types.GomegaMatcher {
+	return FromGomock(match.ErrorIs(target))
+}
+
+func ErrorAs(example error) types.GomegaMatcher {
+	return FromGomock(match.ErrorAs(example))
+}
+
+func ErrorContains(substr string) types.GomegaMatcher {
+	return FromGomock(match.ErrorContains(substr))
+}
+
+func TimeWithin(expected time.Time, tolerance time.Duration) types.GomegaMatcher {
+	return FromGomock(match.TimeWithin(expected, tolerance))
+}
@@ -0,0 +1,127 @@
+package grpcx
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// CaptureFunc receives the values of a call to the method, such as
+// "pkg.Service/Method".
+type CaptureFunc func(method string, values ...interface{})
+
+func methodName(fullMethod string) string {
+	return strings.TrimPrefix(fullMethod, "/")
+}
+
+// Returns an interceptor capturing each unary call's request, then its
+// response unless it failed, then its *status.Status.
+func CaptureUnaryServer(capture CaptureFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		captureUnary(capture, info.FullMethod, req, resp, err)
+		return resp, err
+	}
+}
+
+// Returns an interceptor capturing each message a streaming call receives
+// or sends as it goes, then the call's *status.Status.
+func CaptureStreamServer(capture CaptureFunc) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		method := methodName(info.FullMethod)
+		err := handler(srv, &capturingServerStream{ServerStream: ss, method: method, capture: capture})
+		capture(method, status.Convert(err))
+		return err
+	}
+}
+
+// Returns an interceptor capturing each unary call the client makes the
+// same way CaptureUnaryServer does.
+func CaptureUnaryClient(capture CaptureFunc) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, fullMethod string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, fullMethod, req, reply, cc, opts...)
+		captureUnary(capture, fullMethod, req, reply, err)
+		return err
+	}
+}
+
+// Returns an interceptor capturing each message a streaming call sends or
+// receives, then its *status.Status once the stream ends.
+func CaptureStreamClient(capture CaptureFunc) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, fullMethod string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		method := methodName(fullMethod)
+		cs, err := streamer(ctx, desc, cc, fullMethod, opts...)
+		if err != nil {
+			capture(method, status.Convert(err))
+			return nil, err
+		}
+		return &capturingClientStream{ClientStream: cs, method: method, capture: capture}, nil
+	}
+}
+
+func captureUnary(capture CaptureFunc, fullMethod string, req, resp interface{}, err error) {
+	values := []interface{}{req}
+	if err == nil {
+		values = append(values, resp)
+	}
+	capture(methodName(fullMethod), append(values, status.Convert(err))...)
+}
+
+type capturingServerStream struct {
+	grpc.ServerStream
+	method  string
+	capture CaptureFunc
+}
+
+func (s *capturingServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.capture(s.method, m)
+	}
+	return err
+}
+
+func (s *capturingServerStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.capture(s.method, m)
+	}
+	return err
+}
+
+type capturingClientStream struct {
+	grpc.ClientStream
+	method  string
+	capture CaptureFunc
+}
+
+func (s *capturingClientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.capture(s.method, m)
+	}
+	return err
+}
+
+// Captures the message, or the status once the stream ends with io.EOF
+// or an error.
+func (s *capturingClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		s.capture(s.method, m)
+	} else {
+		s.capture(s.method, status.Convert(statusErr(err)))
+	}
+	return err
+}
+
+// Returns nil for io.EOF, which ends a stream that succeeded.
+func statusErr(err error) error {
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,25 @@
+module github.com/sbernheim/goonit/grpcx
+
+go 1.18
+
+require (
+	github.com/sbernheim/goonit v0.0.0
+	google.golang.org/grpc v1.47.0
+	google.golang.org/protobuf v1.27.1
+)
+
+require (
+	github.com/go-logr/logr v0.4.0 // indirect
+	github.com/golang/mock v1.6.0 // indirect
+	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/google/go-cmp v0.5.6 // indirect
+	github.com/onsi/gomega v1.16.0 // indirect
+	golang.org/x/net v0.0.0-20210428140749-89ef3d95e781 // indirect
+	golang.org/x/sys v0.0.0-20210510120138-977fb7262007 // indirect
+	golang.org/x/text v0.3.6 // indirect
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013 // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+)
+
+// Builds against the goonit in this repository.
+replace github.com/sbernheim/goonit => ../
@@ -0,0 +1,229 @@
+// Package grpcx runs gRPC services in memory for tests, over a bufconn
+// listener instead of a socket:
+//
+//	conn := grpcx.StartConn(x, func(s *grpc.Server) {
+//		pb.RegisterOrdersServer(s, orders)
+//	})
+//	client := pb.NewOrdersClient(conn)
+//
+// The server records every call it handles, with its messages and status,
+// for assertions after the fact.
+//
+// It is a module of its own, so only tests that use it depend on gRPC.
+package grpcx
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// T is the part of *testing.T the server reports failures through.
+type T interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+	Logf(format string, args ...interface{})
+}
+
+// The size of the in-memory connection buffer.
+const BufferSize = 1 << 20
+
+// Call is a call the server handled.  For streaming calls, Requests and
+// Responses hold every message received and sent, and Request and
+// Response the first of each.  The server hands out copies, so a Call
+// from Calls or OnCall holds the messages as of when it was returned and
+// is safe to read while the stream goes on.
+type Call struct {
+	// The full method name, such as "pkg.Service/Method".
+	Method    string
+	Stream    bool
+	Request   interface{}
+	Response  interface{}
+	Requests  []interface{}
+	Responses []interface{}
+	Err       error
+	mu        sync.Mutex
+}
+
+// Returns the call's gRPC status, OK if it succeeded.
+func (c *Call) Status() *status.Status {
+	return status.Convert(c.Err)
+}
+
+// Server is a gRPC server listening in memory, with a ready connection
+// to it in Conn.
+type Server struct {
+	*grpc.Server
+	Conn     *grpc.ClientConn
+	t        T
+	listener *bufconn.Listener
+	mu       sync.Mutex
+	calls    []*Call
+	onCall   []func(*Call)
+}
+
+// Starts a server with the services register adds, recording calls with
+// interceptors that run before any in the options.  Close it when the test
+// is done.
+func NewServer(t T, register func(*grpc.Server), opts ...grpc.ServerOption) *Server {
+	t.Helper()
+	s := &Server{t: t, listener: bufconn.Listen(BufferSize)}
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(s.unary),
+		grpc.ChainStreamInterceptor(s.stream),
+	}, opts...)
+	s.Server = grpc.NewServer(opts...)
+	register(s.Server)
+	go s.Server.Serve(s.listener)
+	s.Conn = s.Dial()
+	return s
+}
+
+// Returns a new connection to the server.  Close closes only Conn, so
+// close connections made with Dial when done with them.
+func (s *Server) Dial(opts ...grpc.DialOption) *grpc.ClientConn {
+	s.t.Helper()
+	opts = append([]grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return s.listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}, opts...)
+	conn, err := grpc.Dial("passthrough:///bufconn", opts...)
+	if err != nil {
+		s.t.Fatalf("failed to dial in-memory gRPC server: %s", err.Error())
+	}
+	return conn
+}
+
+// Calls the function with a copy of each call once it finishes.
+func (s *Server) OnCall(fn func(*Call)) *Server {
+	s.mu.Lock()
+	s.onCall = append(s.onCall, fn)
+	s.mu.Unlock()
+	return s
+}
+
+// Returns a copy of every call the server handled, in the order they
+// started.
+func (s *Server) Calls() []*Call {
+	s.mu.Lock()
+	calls := append([]*Call{}, s.calls...)
+	s.mu.Unlock()
+	for i, c := range calls {
+		calls[i] = c.snapshot()
+	}
+	return calls
+}
+
+// Returns a copy of the call as it stands.
+func (c *Call) snapshot() *Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &Call{
+		Method:    c.Method,
+		Stream:    c.Stream,
+		Request:   c.Request,
+		Response:  c.Response,
+		Requests:  append([]interface{}{}, c.Requests...),
+		Responses: append([]interface{}{}, c.Responses...),
+		Err:       c.Err,
+	}
+}
+
+// Returns the calls to the method, "pkg.Service/Method".
+func (s *Server) CallsTo(method string) []*Call {
+	calls := []*Call{}
+	for _, c := range s.Calls() {
+		if c.Method == strings.TrimPrefix(method, "/") {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Closes Conn and stops the server, ending calls still in progress.
+func (s *Server) Close() {
+	s.Conn.Close()
+	s.Server.Stop()
+	s.listener.Close()
+}
+
+func (s *Server) start(fullMethod string, stream bool) *Call {
+	c := &Call{Method: strings.TrimPrefix(fullMethod, "/"), Stream: stream}
+	s.mu.Lock()
+	s.calls = append(s.calls, c)
+	s.mu.Unlock()
+	return c
+}
+
+func (s *Server) finish(c *Call) {
+	s.mu.Lock()
+	onCall := append([]func(*Call){}, s.onCall...)
+	s.mu.Unlock()
+	for _, fn := range onCall {
+		fn(c.snapshot())
+	}
+}
+
+func (s *Server) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	c := s.start(info.FullMethod, false)
+	resp, err := handler(ctx, req)
+	c.mu.Lock()
+	c.Request, c.Requests, c.Err = req, []interface{}{req}, err
+	if err == nil {
+		c.Response, c.Responses = resp, []interface{}{resp}
+	}
+	c.mu.Unlock()
+	s.finish(c)
+	return resp, err
+}
+
+func (s *Server) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	c := s.start(info.FullMethod, true)
+	err := handler(srv, &recordingStream{ServerStream: ss, call: c})
+	c.mu.Lock()
+	c.Err = err
+	c.mu.Unlock()
+	s.finish(c)
+	return err
+}
+
+// recordingStream records the messages a streaming call receives and sends.
+type recordingStream struct {
+	grpc.ServerStream
+	call *Call
+}
+
+func (r *recordingStream) RecvMsg(m interface{}) error {
+	err := r.ServerStream.RecvMsg(m)
+	if err == nil {
+		r.call.mu.Lock()
+		if r.call.Request == nil {
+			r.call.Request = m
+		}
+		r.call.Requests = append(r.call.Requests, m)
+		r.call.mu.Unlock()
+	}
+	return err
+}
+
+func (r *recordingStream) SendMsg(m interface{}) error {
+	err := r.ServerStream.SendMsg(m)
+	if err == nil {
+		r.call.mu.Lock()
+		if r.call.Response == nil {
+			r.call.Response = m
+		}
+		r.call.Responses = append(r.call.Responses, m)
+		r.call.mu.Unlock()
+	}
+	return err
+}
@@ -0,0 +1,208 @@
+package grpcx
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/sbernheim/goonit/core"
+)
+
+// echoService is a hand-written service, as protoc-gen-go-grpc would
+// generate it, with a unary Upper and a server-streaming Split.
+var echoService = grpc.ServiceDesc{
+	ServiceName: "test.Echo",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Upper",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := &wrapperspb.StringValue{}
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				value := req.(*wrapperspb.StringValue).Value
+				if value == "" {
+					return nil, status.Error(codes.InvalidArgument, "nothing to upper")
+				}
+				return wrapperspb.String(strings.ToUpper(value)), nil
+			}
+			if interceptor == nil {
+				return handler(ctx, req)
+			}
+			return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.Echo/Upper"}, handler)
+		},
+	}},
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Split",
+		ServerStreams: true,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			req := &wrapperspb.StringValue{}
+			if err := stream.RecvMsg(req); err != nil {
+				return err
+			}
+			for _, word := range strings.Fields(req.Value) {
+				if err := stream.SendMsg(wrapperspb.String(word)); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}},
+}
+
+func registerEcho(s *grpc.Server) {
+	s.RegisterService(&echoService, struct{}{})
+}
+
+func upper(t *testing.T, conn *grpc.ClientConn, value string) (*wrapperspb.StringValue, error) {
+	t.Helper()
+	resp := &wrapperspb.StringValue{}
+	err := conn.Invoke(context.Background(), "/test.Echo/Upper", wrapperspb.String(value), resp)
+	return resp, err
+}
+
+func split(t *testing.T, conn *grpc.ClientConn, value string) []string {
+	t.Helper()
+	stream, err := conn.NewStream(context.Background(), &echoService.Streams[0], "/test.Echo/Split")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.SendMsg(wrapperspb.String(value)); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	words := []string{}
+	for {
+		resp := &wrapperspb.StringValue{}
+		err := stream.RecvMsg(resp)
+		if err == io.EOF {
+			return words
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		words = append(words, resp.Value)
+	}
+}
+
+func value(m interface{}) string {
+	return m.(*wrapperspb.StringValue).Value
+}
+
+func TestServerRecordsCalls(t *testing.T) {
+	s := NewServer(t, registerEcho)
+	defer s.Close()
+	if resp, err := upper(t, s.Conn, "hi"); err != nil || resp.Value != "HI" {
+		t.Fatalf("Upper returned %v, %v", resp, err)
+	}
+	if _, err := upper(t, s.Conn, ""); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Upper of nothing returned %v", err)
+	}
+	if words := split(t, s.Conn, "a b c"); len(words) != 3 {
+		t.Fatalf("Split returned %v", words)
+	}
+
+	if calls := s.Calls(); len(calls) != 3 {
+		t.Fatalf("recorded %d calls, expected 3", len(calls))
+	}
+	uppers := s.CallsTo("/test.Echo/Upper")
+	if len(uppers) != 2 {
+		t.Fatalf("recorded %d calls to Upper, expected 2", len(uppers))
+	}
+	if c := uppers[0]; c.Stream || value(c.Request) != "hi" || value(c.Response) != "HI" || c.Status().Code() != codes.OK {
+		t.Errorf("recorded Upper call %+v", c)
+	}
+	if c := uppers[1]; c.Response != nil || c.Status().Code() != codes.InvalidArgument {
+		t.Errorf("recorded failed Upper call %+v", c)
+	}
+	splits := s.CallsTo("test.Echo/Split")
+	if len(splits) != 1 {
+		t.Fatalf("recorded %d calls to Split, expected 1", len(splits))
+	}
+	c := splits[0]
+	if !c.Stream || value(c.Request) != "a b c" || len(c.Responses) != 3 || value(c.Responses[2]) != "c" || c.Err != nil {
+		t.Errorf("recorded Split call %+v", c)
+	}
+}
+
+// Expects the values captured from the method to be the messages and
+// status, in order.
+func expectCaptured(t *testing.T, x *core.BaseTest, method string, messages []string, code codes.Code) {
+	t.Helper()
+	captured := x.CapturedFrom(method)
+	if len(captured) != len(messages)+1 {
+		t.Fatalf("captured %v from %s", captured, method)
+	}
+	for i, m := range messages {
+		if got, ok := captured[i].(proto.Message); !ok || value(got) != m {
+			t.Errorf("captured %v from %s at %d, expected %q", captured[i], method, i, m)
+		}
+	}
+	if st, ok := captured[len(messages)].(*status.Status); !ok || st.Code() != code {
+		t.Errorf("captured %v from %s last, expected status %s", captured[len(messages)], method, code)
+	}
+}
+
+func TestStartCapturesCalls(t *testing.T) {
+	x := core.New(t, core.WithoutMocks())
+	defer x.Done()
+	conn := StartConn(x, registerEcho)
+	upper(t, conn, "hi")
+	split(t, conn, "a b")
+	expectCaptured(t, x, "test.Echo/Upper", []string{"hi", "HI"}, codes.OK)
+	expectCaptured(t, x, "test.Echo/Split", []string{"a b", "a", "b"}, codes.OK)
+}
+
+func TestCaptureClientCapturesCalls(t *testing.T) {
+	s := NewServer(t, registerEcho)
+	defer s.Close()
+	x := core.New(t, core.WithoutMocks())
+	defer x.Done()
+	conn := s.Dial(CaptureClient(x)...)
+	defer conn.Close()
+	upper(t, conn, "hi")
+	split(t, conn, "a b")
+	expectCaptured(t, x, "test.Echo/Upper", []string{"hi", "HI"}, codes.OK)
+	expectCaptured(t, x, "test.Echo/Split", []string{"a b", "a", "b"}, codes.OK)
+}
+
+func TestCallsAreCopies(t *testing.T) {
+	s := NewServer(t, registerEcho)
+	defer s.Close()
+	split(t, s.Conn, "a b")
+	c := s.Calls()[0]
+	c.Responses[0] = nil
+	if s.Calls()[0].Responses[0] == nil {
+		t.Errorf("changing a returned call changed the recorded one")
+	}
+}
+
+func TestCallsCanBeReadWhileStreaming(t *testing.T) {
+	s := NewServer(t, registerEcho)
+	defer s.Close()
+	stream, err := s.Conn.NewStream(context.Background(), &echoService.Streams[0], "/test.Echo/Split")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.SendMsg(wrapperspb.String(strings.Repeat("word ", 100))); err != nil {
+		t.Fatal(err)
+	}
+	stream.CloseSend()
+	for {
+		for _, c := range s.Calls() {
+			_ = len(c.Responses)
+		}
+		if err := stream.RecvMsg(&wrapperspb.StringValue{}); err != nil {
+			break
+		}
+	}
+}
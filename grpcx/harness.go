@@ -0,0 +1,50 @@
+package grpcx
+
+import (
+	"google.golang.org/grpc"
+
+	"github.com/sbernheim/goonit/core"
+)
+
+// Starts an in-memory gRPC server with the services register adds, shut
+// down when the test is Done, and returns it with a ready connection in
+// its Conn field.  The server records every call it handles and captures
+// them as CaptureServer does.
+func Start(x *core.BaseTest, register func(*grpc.Server), opts ...grpc.ServerOption) *Server {
+	opts = append(CaptureServer(x), opts...)
+	s := NewServer(x.TestingT(), register, opts...)
+	x.DoAfter(s.Close)
+	return s
+}
+
+// Starts an in-memory gRPC server with the services register adds, shut
+// down when the test is Done, and returns a ready connection to it.
+func StartConn(x *core.BaseTest, register func(*grpc.Server), opts ...grpc.ServerOption) *grpc.ClientConn {
+	return Start(x, register, opts...).Conn
+}
+
+// Returns server options with interceptors capturing each call's messages
+// and *status.Status, so x.CapturedFrom("pkg.Service/Method") returns them
+// the way it returns a mock call's captures.
+func CaptureServer(x *core.BaseTest) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(CaptureUnaryServer(captureTo(x))),
+		grpc.ChainStreamInterceptor(CaptureStreamServer(captureTo(x))),
+	}
+}
+
+// Returns dial options with interceptors capturing the calls a client
+// makes, for code under test calling servers other than one from Start,
+// which already captures its calls.
+func CaptureClient(x *core.BaseTest) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(CaptureUnaryClient(captureTo(x))),
+		grpc.WithChainStreamInterceptor(CaptureStreamClient(captureTo(x))),
+	}
+}
+
+func captureTo(x *core.BaseTest) CaptureFunc {
+	return func(method string, values ...interface{}) {
+		x.CaptureAs(method, values...)
+	}
+}
@@ -0,0 +1,273 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Set GOONIT_HTTP_RECORD to any non-empty value to have cassettes record
+// real requests and responses instead of replaying them.
+const RecordEnv = "GOONIT_HTTP_RECORD"
+
+// Headers whose values are replaced before a cassette is saved.
+var DefaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+const redacted = "REDACTED"
+
+// Cassette is the file format for recorded interactions.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+type CassetteRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitempty"`
+}
+
+type CassetteResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitempty"`
+}
+
+// Body is saved as a string when it is valid UTF-8 and base64 otherwise.
+type Body []byte
+
+func (b Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal(map[string]string{"base64": base64.StdEncoding.EncodeToString(b)})
+}
+
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*b = Body(s)
+		return nil
+	}
+	var encoded map[string]string
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded["base64"])
+	*b = decoded
+	return err
+}
+
+// Recorder is an http.RoundTripper that records interactions with real
+// services to a cassette file, when GOONIT_HTTP_RECORD is set, and
+// otherwise replays them from it, so tests of API clients run hermetically.
+type Recorder struct {
+	t             T
+	path          string
+	recording     bool
+	base          http.RoundTripper
+	redactHeaders []string
+	redactQuery   []string
+	mu            sync.Mutex
+	cassette      *Cassette
+	used          []bool
+}
+
+// Returns a recorder for the cassette file.  Recording sends requests
+// through base, or http.DefaultTransport if it is nil.  Replaying fails
+// the test if the cassette does not exist.
+func NewRecorder(t T, path string, base http.RoundTripper) *Recorder {
+	t.Helper()
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	r := &Recorder{
+		t:             t,
+		path:          path,
+		recording:     os.Getenv(RecordEnv) != "",
+		base:          base,
+		redactHeaders: append([]string{}, DefaultRedactedHeaders...),
+		cassette:      &Cassette{},
+	}
+	if r.recording {
+		return r
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read cassette '%s' (set %s=1 to record it): %s", path, RecordEnv, err.Error())
+	}
+	if err := json.Unmarshal(data, r.cassette); err != nil {
+		t.Fatalf("failed to parse cassette '%s': %s", path, err.Error())
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+	return r
+}
+
+// Returns true if the recorder is recording rather than replaying.
+func (r *Recorder) Recording() bool {
+	return r.recording
+}
+
+// Adds headers to redact from saved requests and responses.
+func (r *Recorder) RedactHeaders(names ...string) *Recorder {
+	r.redactHeaders = append(r.redactHeaders, names...)
+	return r
+}
+
+// Adds query parameters, such as API keys, to redact from saved URLs.
+// Replayed requests are matched with the same parameters redacted.
+func (r *Recorder) RedactQuery(names ...string) *Recorder {
+	r.redactQuery = append(r.redactQuery, names...)
+	return r
+}
+
+// Returns a client that sends requests through the recorder.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+func (r *Recorder) redactURL(u *url.URL) string {
+	if len(r.redactQuery) == 0 {
+		return u.String()
+	}
+	redactedURL := *u
+	query := redactedURL.Query()
+	for _, name := range r.redactQuery {
+		if _, found := query[name]; found {
+			query.Set(name, redacted)
+		}
+	}
+	redactedURL.RawQuery = query.Encode()
+	return redactedURL.String()
+}
+
+func (r *Recorder) redactHeader(h http.Header) http.Header {
+	h = h.Clone()
+	for _, name := range r.redactHeaders {
+		if _, found := h[http.CanonicalHeaderKey(name)]; found {
+			h.Set(name, redacted)
+		}
+	}
+	return h
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.recording {
+		return r.record(req, recorded)
+	}
+	return r.replay(req, recorded)
+}
+
+func (r *Recorder) record(req *http.Request, recorded *Request) (*http.Response, error) {
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, &Interaction{
+		Request: CassetteRequest{
+			Method: req.Method,
+			URL:    r.redactURL(req.URL),
+			Header: r.redactHeader(recorded.Header),
+			Body:   recorded.Body,
+		},
+		Response: CassetteResponse{
+			Status: resp.StatusCode,
+			Header: r.redactHeader(resp.Header),
+			Body:   body,
+		},
+	})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Replays the first unused interaction with the same method, URL and body.
+func (r *Recorder) replay(req *http.Request, recorded *Request) (*http.Response, error) {
+	u := r.redactURL(req.URL)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || in.Request.Method != req.Method || in.Request.URL != u || !bytes.Equal(in.Request.Body, recorded.Body) {
+			continue
+		}
+		r.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+			StatusCode:    in.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Response.Header.Clone(),
+			Body:          ioutil.NopCloser(bytes.NewReader(in.Response.Body)),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	r.t.Errorf("cassette '%s' has no unused interaction for %s %s (set %s=1 to re-record it)", r.path, req.Method, u, RecordEnv)
+	return nil, fmt.Errorf("httpx: no recorded interaction for %s %s", req.Method, u)
+}
+
+// Writes the recorded interactions to the cassette file.  Does nothing
+// when replaying.
+func (r *Recorder) Save() error {
+	if !r.recording {
+		return nil
+	}
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	r.mu.Lock()
+	err := enc.Encode(r.cassette)
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0777); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, buf.Bytes(), 0666)
+}
+
+// Returns the recorded interactions that were not replayed.
+func (r *Recorder) Unused() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	unused := []string{}
+	for i, in := range r.cassette.Interactions {
+		if !r.recording && !r.used[i] {
+			unused = append(unused, strings.Join([]string{in.Request.Method, in.Request.URL}, " "))
+		}
+	}
+	return unused
+}
@@ -0,0 +1,159 @@
+package httpx
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/sbernheim/goonit/match"
+)
+
+// RequestAssert narrows down the requests a Server received.  Each method
+// fails the test if no received request is left that satisfies every
+// condition so far.
+type RequestAssert struct {
+	t          T
+	all        []*Request
+	candidates []*Request
+	conditions []string
+}
+
+// Fails the test unless the server received a request with the method and
+// path, and returns an assertion for narrowing it down further, the same
+// way gomock matchers narrow down expected calls.
+func (s *Server) ExpectRequest(method, path string) *RequestAssert {
+	s.t.Helper()
+	a := &RequestAssert{t: s.t, all: s.Requests()}
+	a.candidates = a.all
+	return a.filter(fmt.Sprintf("%s %s", strings.ToUpper(method), path), func(r *Request) bool {
+		return strings.EqualFold(r.Method, method) && r.URL.Path == path
+	})
+}
+
+// Returns a matcher for the value: the value itself if it is a
+// gomock.Matcher, or gomock.Eq of it.
+func matcherFor(value interface{}) gomock.Matcher {
+	if m, ok := value.(gomock.Matcher); ok {
+		return m
+	}
+	return gomock.Eq(value)
+}
+
+// Keeps the requests with a header value matching the value, which is a
+// string or a gomock.Matcher such as match.AnyString().
+func (a *RequestAssert) WithHeader(name string, value interface{}) *RequestAssert {
+	a.t.Helper()
+	m := matcherFor(value)
+	return a.filter(fmt.Sprintf("header %s %s", name, m), func(r *Request) bool {
+		for _, v := range r.Header.Values(name) {
+			if m.Matches(v) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Keeps the requests with a query parameter value matching the value.
+func (a *RequestAssert) WithQuery(name string, value interface{}) *RequestAssert {
+	a.t.Helper()
+	m := matcherFor(value)
+	return a.filter(fmt.Sprintf("query %s %s", name, m), func(r *Request) bool {
+		for _, v := range r.URL.Query()[name] {
+			if m.Matches(v) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Keeps the requests whose body matches: a string is compared as JSON with
+// match.JSONEq, a gomock.Matcher is tried against both the raw body and its
+// decoded JSON value, and any other value is compared as its JSON encoding.
+func (a *RequestAssert) WithJSONBody(expected interface{}) *RequestAssert {
+	a.t.Helper()
+	var m gomock.Matcher
+	switch e := expected.(type) {
+	case string:
+		m = match.JSONEq(e)
+	case gomock.Matcher:
+		m = e
+	default:
+		data, err := json.Marshal(e)
+		if err != nil {
+			a.t.Fatalf("failed to encode expected request body as JSON: %s", err.Error())
+		}
+		m = match.JSONEq(string(data))
+	}
+	return a.filter(fmt.Sprintf("JSON body %s", m), func(r *Request) bool {
+		if m.Matches(r.Body) {
+			return true
+		}
+		var decoded interface{}
+		return json.Unmarshal(r.Body, &decoded) == nil && m.Matches(decoded)
+	})
+}
+
+// Fails the test unless exactly n received requests satisfy the conditions.
+func (a *RequestAssert) Times(n int) *RequestAssert {
+	a.t.Helper()
+	if len(a.candidates) != n {
+		a.t.Fatalf("expected %d requests %s but got %d\n%s", n, a.describe(), len(a.candidates), a.received())
+	}
+	return a
+}
+
+// Returns the received requests that satisfy the conditions.
+func (a *RequestAssert) Requests() []*Request {
+	return a.candidates
+}
+
+// Returns the first received request that satisfies the conditions.
+func (a *RequestAssert) First() *Request {
+	return a.candidates[0]
+}
+
+func (a *RequestAssert) filter(condition string, keep func(r *Request) bool) *RequestAssert {
+	a.t.Helper()
+	a.conditions = append(a.conditions, condition)
+	kept := []*Request{}
+	for _, r := range a.candidates {
+		if keep(r) {
+			kept = append(kept, r)
+		}
+	}
+	a.candidates = kept
+	if len(kept) == 0 {
+		a.t.Fatalf("expected a request %s\n%s", a.describe(), a.received())
+	}
+	return a
+}
+
+func (a *RequestAssert) describe() string {
+	return strings.Join(a.conditions, ", with ")
+}
+
+func (a *RequestAssert) received() string {
+	if len(a.all) == 0 {
+		return "no requests were received"
+	}
+	b := &strings.Builder{}
+	b.WriteString("received:")
+	for _, r := range a.all {
+		fmt.Fprintf(b, "\n  %s %s", r.Method, r.URL)
+		if len(r.Body) > 0 {
+			fmt.Fprintf(b, " %s", truncate(string(r.Body), 200))
+		}
+	}
+	return b.String()
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,248 @@
+package httpx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang/mock/gomock"
+)
+
+// GraphQLRequest is an operation a GraphQLServer received.
+type GraphQLRequest struct {
+	OperationName string                 `json:"operationName"`
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// GraphQLError is an entry in the errors of a GraphQL response.
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+var operationNamePattern = regexp.MustCompile(`^\s*(?:query|mutation|subscription)\s+([_A-Za-z][_0-9A-Za-z]*)`)
+
+// Returns the operation name sent with the request, or else the name in
+// its query document.
+func (r *GraphQLRequest) name() string {
+	if r.OperationName != "" {
+		return r.OperationName
+	}
+	if m := operationNamePattern.FindStringSubmatch(r.Query); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// GraphQLOperation is a stubbed reply to operations with a name.
+type GraphQLOperation struct {
+	t         T
+	name      string
+	variables map[string]gomock.Matcher
+	data      interface{}
+	errors    []GraphQLError
+	times     int
+	mu        sync.Mutex
+	hits      int
+}
+
+// Narrows the operation to requests whose variables match.  Each value is a
+// gomock.Matcher or a value compared with its JSON encoding, and variables
+// left out may have any value.
+func (o *GraphQLOperation) WithVariables(variables map[string]interface{}) *GraphQLOperation {
+	o.t.Helper()
+	for name, value := range variables {
+		m, err := jsonValueMatcher(value)
+		if err != nil {
+			o.t.Fatalf("failed to encode GraphQL variable '%s' as JSON: %s", name, err.Error())
+		}
+		o.variables[name] = m
+	}
+	return o
+}
+
+// Replies with the value as the response's data.
+func (o *GraphQLOperation) Reply(data interface{}) *GraphQLOperation {
+	o.data = data
+	return o
+}
+
+// Replies with errors with the messages, and null data unless Reply is
+// also used.
+func (o *GraphQLOperation) ReplyErrors(messages ...string) *GraphQLOperation {
+	for _, message := range messages {
+		o.errors = append(o.errors, GraphQLError{Message: message})
+	}
+	return o
+}
+
+func (o *GraphQLOperation) ReplyError(err GraphQLError) *GraphQLOperation {
+	o.errors = append(o.errors, err)
+	return o
+}
+
+// Limits the operation to n replies, after which later stubs are tried.
+func (o *GraphQLOperation) Times(n int) *GraphQLOperation {
+	o.times = n
+	return o
+}
+
+// Returns how many requests the operation replied to.
+func (o *GraphQLOperation) Hits() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.hits
+}
+
+func (o *GraphQLOperation) matches(r *GraphQLRequest) bool {
+	if o.name != r.name() {
+		return false
+	}
+	for name, m := range o.variables {
+		if !m.Matches(r.Variables[name]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (o *GraphQLOperation) claim() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.times > 0 && o.hits >= o.times {
+		return false
+	}
+	o.hits++
+	return true
+}
+
+func (o *GraphQLOperation) String() string {
+	if len(o.variables) == 0 {
+		return o.name
+	}
+	names := make([]string, 0, len(o.variables))
+	for name, m := range o.variables {
+		names = append(names, fmt.Sprintf("%s %s", name, m))
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("%s(%s)", o.name, strings.Join(names, ", "))
+}
+
+// GraphQLServer is an httptest.Server replying to GraphQL operations by
+// name and variables.
+type GraphQLServer struct {
+	*httptest.Server
+	t          T
+	mu         sync.Mutex
+	operations []*GraphQLOperation
+	received   []*GraphQLRequest
+}
+
+// Starts a GraphQL server reporting unmatched operations through t.  It
+// accepts operations POSTed as JSON, or sent with GET as query, variables
+// and operationName parameters, to any path.  Close it when the test is
+// done.
+func NewGraphQLServer(t T) *GraphQLServer {
+	s := &GraphQLServer{t: t}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Stubs a reply for operations with the name.  Operations are tried in the
+// order they were stubbed.
+func (s *GraphQLServer) On(operationName string) *GraphQLOperation {
+	o := &GraphQLOperation{t: s.t, name: operationName, variables: map[string]gomock.Matcher{}}
+	s.mu.Lock()
+	s.operations = append(s.operations, o)
+	s.mu.Unlock()
+	return o
+}
+
+// Returns every operation the server received, in order.
+func (s *GraphQLServer) Received() []*GraphQLRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*GraphQLRequest{}, s.received...)
+}
+
+// Fails the test unless the server received an operation with the name
+// whose variables match, as for WithVariables, and returns the first one.
+func (s *GraphQLServer) ExpectOperation(operationName string, variables map[string]interface{}) *GraphQLRequest {
+	s.t.Helper()
+	o := (&GraphQLOperation{t: s.t, name: operationName, variables: map[string]gomock.Matcher{}}).WithVariables(variables)
+	received := s.Received()
+	for _, r := range received {
+		if o.matches(r) {
+			return r
+		}
+	}
+	names := []string{}
+	for _, r := range received {
+		vars, _ := json.Marshal(r.Variables)
+		names = append(names, fmt.Sprintf("%s %s", r.name(), vars))
+	}
+	s.t.Fatalf("expected GraphQL operation %s but received [%s]", o, strings.Join(names, ", "))
+	return nil
+}
+
+func (s *GraphQLServer) find(r *GraphQLRequest) *GraphQLOperation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, o := range s.operations {
+		if o.matches(r) && o.claim() {
+			return o
+		}
+	}
+	return nil
+}
+
+func (s *GraphQLServer) stubbed() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := []string{}
+	for _, o := range s.operations {
+		names = append(names, o.String())
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
+
+func (s *GraphQLServer) serve(w http.ResponseWriter, req *http.Request) {
+	r := &GraphQLRequest{}
+	if req.Method == http.MethodGet {
+		r.OperationName, r.Query = req.URL.Query().Get("operationName"), req.URL.Query().Get("query")
+		if vars := req.URL.Query().Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &r.Variables); err != nil {
+				s.t.Errorf("failed to decode GraphQL variables in %s: %s", req.URL, err.Error())
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+	} else if err := json.NewDecoder(req.Body).Decode(r); err != nil {
+		s.t.Errorf("failed to decode GraphQL request to %s: %s", req.URL, err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.received = append(s.received, r)
+	s.mu.Unlock()
+	reply := map[string]interface{}{}
+	if o := s.find(r); o != nil {
+		reply["data"] = o.data
+		if len(o.errors) > 0 {
+			reply["errors"] = o.errors
+		}
+	} else {
+		s.t.Errorf("GraphQL server received unexpected operation '%s'; stubbed operations are %s", r.name(), s.stubbed())
+		reply["data"] = nil
+		reply["errors"] = []GraphQLError{{Message: fmt.Sprintf("no stub for operation '%s'", r.name())}}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reply)
+}
@@ -0,0 +1,37 @@
+package httpx
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// errorT records the errors of a T instead of failing the test.
+type errorT struct {
+	*testing.T
+	errors []string
+}
+
+func (t *errorT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, format)
+}
+
+func TestGraphQLServerRejectsMalformedGETVariables(t *testing.T) {
+	et := &errorT{T: t}
+	s := NewGraphQLServer(et)
+	defer s.Close()
+	s.On("User").WithVariables(map[string]interface{}{}).Reply(map[string]interface{}{"user": nil})
+	query := url.Values{"operationName": {"User"}, "query": {"query User { user { id } }"}, "variables": {"{bad"}}
+	resp, err := http.Get(s.URL + "/graphql?" + query.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("replied %d, expected 400", resp.StatusCode)
+	}
+	if len(et.errors) != 1 || !strings.Contains(et.errors[0], "failed to decode GraphQL variables") {
+		t.Errorf("failed with %q", et.errors)
+	}
+}
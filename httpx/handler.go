@@ -0,0 +1,214 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+
+	"github.com/golang/mock/gomock"
+)
+
+// HandlerRequest builds a request to send straight to an http.Handler,
+// without a server.
+type HandlerRequest struct {
+	t      T
+	method string
+	target string
+	header http.Header
+	query  [][2]string
+	body   []byte
+}
+
+// Starts building a request for the method and target, a path with an
+// optional query.
+func NewHandlerRequest(t T, method, target string) *HandlerRequest {
+	return &HandlerRequest{t: t, method: method, target: target, header: http.Header{}}
+}
+
+func (r *HandlerRequest) WithHeader(name, value string) *HandlerRequest {
+	r.header.Add(name, value)
+	return r
+}
+
+// Adds a query parameter to those already in the target.
+func (r *HandlerRequest) WithQuery(name, value string) *HandlerRequest {
+	r.query = append(r.query, [2]string{name, value})
+	return r
+}
+
+func (r *HandlerRequest) WithBody(body []byte) *HandlerRequest {
+	r.body = body
+	return r
+}
+
+// Sets the body to the value encoded as JSON, and the Content-Type to
+// application/json unless it is already set.
+func (r *HandlerRequest) WithJSON(body interface{}) *HandlerRequest {
+	r.t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		r.t.Fatalf("failed to encode request body as JSON: %s", err.Error())
+	}
+	if r.header.Get("Content-Type") == "" {
+		r.header.Set("Content-Type", "application/json")
+	}
+	return r.WithBody(data)
+}
+
+// Returns the request as built.
+func (r *HandlerRequest) Build() *http.Request {
+	req := httptest.NewRequest(r.method, r.target, bytes.NewReader(r.body))
+	for name, values := range r.header {
+		req.Header[name] = append([]string{}, values...)
+	}
+	if len(r.query) > 0 {
+		q := req.URL.Query()
+		for _, kv := range r.query {
+			q.Add(kv[0], kv[1])
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+	return req
+}
+
+// Sends the request to the handler and returns its response.
+func (r *HandlerRequest) Do(handler http.Handler) *HandlerResponse {
+	req := r.Build()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	resp := rec.Result()
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	return &HandlerResponse{t: r.t, Response: resp, Body: body, desc: fmt.Sprintf("%s %s", req.Method, req.URL)}
+}
+
+// HandlerResponse is a handler's response with assertions on it.
+type HandlerResponse struct {
+	*http.Response
+	Body []byte
+	t    T
+	desc string
+}
+
+func (r *HandlerResponse) fail(format string, args ...interface{}) {
+	r.t.Helper()
+	r.t.Fatalf("%s: %s\nresponse %s: %s", r.desc, fmt.Sprintf(format, args...), r.Status, truncate(string(r.Body), 500))
+}
+
+func (r *HandlerResponse) ExpectStatus(code int) *HandlerResponse {
+	r.t.Helper()
+	if r.StatusCode != code {
+		r.fail("expected status %d but got %d", code, r.StatusCode)
+	}
+	return r
+}
+
+// Fails the test unless a value of the header matches the value, which is
+// a string or a gomock.Matcher such as match.AnyString().
+func (r *HandlerResponse) ExpectHeader(name string, value interface{}) *HandlerResponse {
+	r.t.Helper()
+	m := matcherFor(value)
+	for _, v := range r.Header.Values(name) {
+		if m.Matches(v) {
+			return r
+		}
+	}
+	r.fail("expected header %s %s but got %q", name, m, r.Header.Values(name))
+	return r
+}
+
+// Decodes the JSON body into v.
+func (r *HandlerResponse) JSON(v interface{}) *HandlerResponse {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		r.fail("failed to decode JSON body: %s", err.Error())
+	}
+	return r
+}
+
+// Fails the test unless the value at the path in the JSON body matches.
+// The path is a dotted path such as "$.items[0].id".  The expected value
+// is a gomock.Matcher, tried against the decoded value, or any value,
+// compared with its JSON encoding so 1 equals a decoded 1.0.
+func (r *HandlerResponse) ExpectJSONPath(path string, expected interface{}) *HandlerResponse {
+	r.t.Helper()
+	var doc interface{}
+	r.JSON(&doc)
+	actual, err := lookupJSONPath(doc, path)
+	if err != nil {
+		r.fail("failed to look up '%s': %s", path, err.Error())
+	}
+	m, err := jsonValueMatcher(expected)
+	if err != nil {
+		r.t.Fatalf("failed to encode expected value for '%s' as JSON: %s", path, err.Error())
+	}
+	if !m.Matches(actual) {
+		r.fail("expected %s %s but got %#v", path, m, actual)
+	}
+	return r
+}
+
+// Returns the value itself if it is a gomock.Matcher, or a matcher for a
+// decoded JSON value equal to its JSON encoding.
+func jsonValueMatcher(value interface{}) (gomock.Matcher, error) {
+	if m, ok := value.(gomock.Matcher); ok {
+		return m, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return gomock.Eq(decoded), nil
+}
+
+// Looks up a path of object keys and array indexes, such as
+// "$.items[0].id", in a decoded JSON document.
+func lookupJSONPath(doc interface{}, path string) (interface{}, error) {
+	rest := strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	v := doc
+	for rest != "" {
+		var key string
+		if strings.HasPrefix(rest, "[") {
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed '[' in path")
+			}
+			key, rest = rest[:end+1], strings.TrimPrefix(rest[end+1:], ".")
+		} else {
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key, rest = rest[:end], strings.TrimPrefix(rest[end:], ".")
+		}
+		if strings.HasPrefix(key, "[") {
+			i, err := strconv.Atoi(key[1 : len(key)-1])
+			arr, ok := v.([]interface{})
+			if err != nil || !ok {
+				return nil, fmt.Errorf("cannot index %#v with %s", v, key)
+			}
+			if i < 0 || i >= len(arr) {
+				return nil, fmt.Errorf("index %d out of range for array of length %d", i, len(arr))
+			}
+			v = arr[i]
+			continue
+		}
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cannot look up '%s' in %#v", key, v)
+		}
+		if v, ok = obj[key]; !ok {
+			return nil, fmt.Errorf("no field '%s'", key)
+		}
+	}
+	return v, nil
+}
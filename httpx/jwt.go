@@ -0,0 +1,158 @@
+package httpx
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/sbernheim/goonit/clock"
+)
+
+// The path ServeJWKS serves an Issuer's keys at.
+const JWKSPath = "/.well-known/jwks.json"
+
+// Issuer signs RS256 JWTs with an ephemeral RSA key generated for the test.
+type Issuer struct {
+	t     T
+	key   *rsa.PrivateKey
+	clock clock.Clock
+	// The kid header of signed tokens and the kid of the key in the JWKS.
+	KeyID string
+	// The default iss claim of signed tokens.
+	Name string
+}
+
+// Generates a new key to sign tokens with.
+func NewIssuer(t T) *Issuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate JWT signing key: %s", err.Error())
+	}
+	kid := make([]byte, 8)
+	rand.Read(kid)
+	return &Issuer{t: t, key: key, clock: clock.Real(), KeyID: fmt.Sprintf("%x", kid), Name: "goonit"}
+}
+
+// Sets iat, nbf and exp from the clock, such as a test's fake clock, so
+// expiry can be tested without waiting.
+func (i *Issuer) UseClock(c clock.Clock) *Issuer {
+	i.clock = c
+	return i
+}
+
+func (i *Issuer) PublicKey() *rsa.PublicKey {
+	return &i.key.PublicKey
+}
+
+// JWTOption changes the header or claims of a token before it is signed.
+type JWTOption func(j *JWT)
+
+// JWT is a token being signed.
+type JWT struct {
+	Header map[string]interface{}
+	Claims map[string]interface{}
+	// The time from the issuer's clock, as Unix seconds.
+	Now    int64
+	signer *Issuer
+}
+
+// Sets exp to the duration after now; negative durations make expired
+// tokens.
+func JWTExpiresIn(d time.Duration) JWTOption {
+	return func(j *JWT) {
+		j.Claims["exp"] = j.Now + int64(d/time.Second)
+	}
+}
+
+// Makes a token that expired a minute ago.
+func JWTExpired() JWTOption {
+	return JWTExpiresIn(-time.Minute)
+}
+
+// Sets nbf to the duration after now.
+func JWTNotBefore(d time.Duration) JWTOption {
+	return func(j *JWT) {
+		j.Claims["nbf"] = j.Now + int64(d/time.Second)
+	}
+}
+
+// Signs the token with another issuer's key, keeping this issuer's kid, to
+// test that forged signatures are rejected.
+func JWTSignedBy(other *Issuer) JWTOption {
+	return func(j *JWT) {
+		j.signer = other
+	}
+}
+
+func JWTHeader(name string, value interface{}) JWTOption {
+	return func(j *JWT) {
+		j.Header[name] = value
+	}
+}
+
+// Returns a signed token with the claims, plus iss, iat and an exp an hour
+// later unless the claims set them.
+func (i *Issuer) Sign(claims map[string]interface{}, opts ...JWTOption) string {
+	i.t.Helper()
+	now := i.clock.Now().Unix()
+	j := &JWT{
+		Header: map[string]interface{}{"alg": "RS256", "typ": "JWT", "kid": i.KeyID},
+		Claims: map[string]interface{}{"iss": i.Name, "iat": now, "exp": now + 3600},
+		Now:    now,
+		signer: i,
+	}
+	for name, value := range claims {
+		j.Claims[name] = value
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	header, err := json.Marshal(j.Header)
+	if err != nil {
+		i.t.Fatalf("failed to encode JWT header: %s", err.Error())
+	}
+	payload, err := json.Marshal(j.Claims)
+	if err != nil {
+		i.t.Fatalf("failed to encode JWT claims: %s", err.Error())
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	if j.Header["alg"] == "none" {
+		return signed + "."
+	}
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, j.signer.key, crypto.SHA256, digest[:])
+	if err != nil {
+		i.t.Fatalf("failed to sign JWT: %s", err.Error())
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// Returns the JSON Web Key Set with the issuer's public key.
+func (i *Issuer) JWKS() map[string]interface{} {
+	pub := i.PublicKey()
+	return map[string]interface{}{
+		"keys": []map[string]interface{}{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": i.KeyID,
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	}
+}
+
+// Stubs GET JWKSPath on the server with the issuer's JWKS, and returns its
+// URL.
+func (i *Issuer) ServeJWKS(s *Server) string {
+	s.On(http.MethodGet, JWKSPath).ReplyJSON(http.StatusOK, i.JWKS())
+	return s.URL + JWKSPath
+}
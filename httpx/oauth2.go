@@ -0,0 +1,109 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The path NewOAuth2Server serves its token endpoint at.
+const OAuth2TokenPath = "/oauth2/token"
+
+// OAuth2Server is a Server with a token endpoint issuing JWTs to
+// registered clients with the client_credentials grant, and the issuer's
+// JWKS for verifying them.
+type OAuth2Server struct {
+	*Server
+	Issuer  *Issuer
+	mu      sync.Mutex
+	clients map[string]string
+	expiry  time.Duration
+	issued  []string
+}
+
+// Starts a server issuing tokens signed by the issuer, whose Name becomes
+// the server's URL so tokens' iss matches it.  Close it when the test is
+// done.
+func NewOAuth2Server(t T, issuer *Issuer) *OAuth2Server {
+	s := &OAuth2Server{Server: NewServer(t), Issuer: issuer, clients: map[string]string{}, expiry: time.Hour}
+	issuer.Name = s.URL
+	issuer.ServeJWKS(s.Server)
+	s.On(http.MethodPost, OAuth2TokenPath).ReplyWith(s.token)
+	return s
+}
+
+func (s *OAuth2Server) TokenURL() string {
+	return s.URL + OAuth2TokenPath
+}
+
+func (s *OAuth2Server) JWKSURL() string {
+	return s.URL + JWKSPath
+}
+
+// Registers a client allowed to request tokens.
+func (s *OAuth2Server) Client(id, secret string) *OAuth2Server {
+	s.mu.Lock()
+	s.clients[id] = secret
+	s.mu.Unlock()
+	return s
+}
+
+// Sets how long issued tokens are valid, an hour by default.
+func (s *OAuth2Server) ExpiresIn(d time.Duration) *OAuth2Server {
+	s.mu.Lock()
+	s.expiry = d
+	s.mu.Unlock()
+	return s
+}
+
+// Returns every access token the server issued, in order.
+func (s *OAuth2Server) Issued() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.issued...)
+}
+
+func (s *OAuth2Server) token(w http.ResponseWriter, req *http.Request) {
+	fail := func(status int, code string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"error":"` + code + `"}`))
+	}
+	if err := req.ParseForm(); err != nil {
+		fail(http.StatusBadRequest, "invalid_request")
+		return
+	}
+	if grant := req.PostForm.Get("grant_type"); grant != "client_credentials" {
+		fail(http.StatusBadRequest, "unsupported_grant_type")
+		return
+	}
+	id, secret, ok := req.BasicAuth()
+	if !ok {
+		id, secret = req.PostForm.Get("client_id"), req.PostForm.Get("client_secret")
+	}
+	s.mu.Lock()
+	expected, known := s.clients[id]
+	expiry := s.expiry
+	s.mu.Unlock()
+	if !known || secret != expected {
+		fail(http.StatusUnauthorized, "invalid_client")
+		return
+	}
+	claims := map[string]interface{}{"sub": id, "client_id": id}
+	if scope := req.PostForm.Get("scope"); scope != "" {
+		claims["scope"] = strings.Join(strings.Fields(scope), " ")
+	}
+	token := s.Issuer.Sign(claims, JWTExpiresIn(expiry))
+	s.mu.Lock()
+	s.issued = append(s.issued, token)
+	s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_in":   int64(expiry / time.Second),
+	})
+}
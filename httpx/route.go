@@ -0,0 +1,202 @@
+package httpx
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/golang/mock/gomock"
+)
+
+// Route is a stubbed reply to requests with a method and path.
+type Route struct {
+	t       T
+	method  string
+	path    string
+	status  int
+	header  http.Header
+	body    []byte
+	handler http.HandlerFunc
+	when    []gomock.Matcher
+	times   int
+	mu      sync.Mutex
+	hits    int
+}
+
+func newRoute(t T, method, path string) *Route {
+	return &Route{
+		t:      t,
+		method: strings.ToUpper(method),
+		path:   path,
+		status: http.StatusOK,
+		header: http.Header{},
+	}
+}
+
+// Returns true if the route stubs the request: the method matches, or the
+// route's is "*", and the path matches, or the route's ends in "*" and the
+// path starts with the rest of it.
+func (r *Route) matches(req *http.Request) bool {
+	if r.method != "*" && r.method != req.Method {
+		return false
+	}
+	if prefix := strings.TrimSuffix(r.path, "*"); prefix != r.path {
+		if !strings.HasPrefix(req.URL.Path, prefix) {
+			return false
+		}
+	} else if r.path != req.URL.Path {
+		return false
+	}
+	for _, m := range r.when {
+		if !m.Matches(req) {
+			return false
+		}
+	}
+	return true
+}
+
+// Narrows the route to requests the matchers all match.  Each matcher
+// is given the *http.Request.
+func (r *Route) Matching(matchers ...gomock.Matcher) *Route {
+	r.when = append(r.when, matchers...)
+	return r
+}
+
+// Counts a request against the route, unless the route is limited with
+// Times and used up.
+func (r *Route) claim() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.times > 0 && r.hits >= r.times {
+		return false
+	}
+	r.hits++
+	return true
+}
+
+// Replies with the status and body.
+func (r *Route) Reply(status int, body string) *Route {
+	r.status = status
+	r.body = []byte(body)
+	return r
+}
+
+// Replies with the status and the value encoded as JSON.
+func (r *Route) ReplyJSON(status int, v interface{}) *Route {
+	r.t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		r.t.Fatalf("failed to encode reply for %s as JSON: %s", r, err.Error())
+	}
+	r.header.Set("Content-Type", "application/json")
+	r.status = status
+	r.body = data
+	return r
+}
+
+// Replies with the status and the content of the file, such as a fixture in
+// testdata.  The Content-Type is inferred from the file extension, or else
+// from the content, unless set with SetHeader.
+func (r *Route) ReplyFile(status int, path string) *Route {
+	r.t.Helper()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		r.t.Fatalf("failed to read reply file '%s' for %s: %s", path, r, err.Error())
+	}
+	if r.header.Get("Content-Type") == "" {
+		contentType := mime.TypeByExtension(filepath.Ext(path))
+		if contentType == "" {
+			contentType = http.DetectContentType(data)
+		}
+		r.header.Set("Content-Type", contentType)
+	}
+	r.status = status
+	r.body = data
+	return r
+}
+
+// Replies by calling the handler, for replies that depend on the request.
+func (r *Route) ReplyWith(handler http.HandlerFunc) *Route {
+	r.handler = handler
+	return r
+}
+
+// Sets a header on the reply.
+func (r *Route) SetHeader(name, value string) *Route {
+	r.header.Set(name, value)
+	return r
+}
+
+// Limits the route to n requests, after which later routes, or a 404, reply.
+func (r *Route) Times(n int) *Route {
+	r.times = n
+	return r
+}
+
+// Returns how many requests the route replied to.
+func (r *Route) Hits() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.hits
+}
+
+func (r *Route) String() string {
+	s := r.method + " " + r.path
+	for _, m := range r.when {
+		s += " matching " + m.String()
+	}
+	return s
+}
+
+func (r *Route) serve(w http.ResponseWriter, req *http.Request) {
+	if r.handler != nil {
+		r.handler(w, req)
+		return
+	}
+	for name, values := range r.header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(r.status)
+	w.Write(r.body)
+}
+
+// routes holds routes in the order they were stubbed; the first matching
+// one replies.
+type routes struct {
+	t      T
+	mu     sync.Mutex
+	routes []*Route
+}
+
+func (rs *routes) add(method, path string) *Route {
+	r := newRoute(rs.t, method, path)
+	rs.mu.Lock()
+	rs.routes = append(rs.routes, r)
+	rs.mu.Unlock()
+	return r
+}
+
+func (rs *routes) find(req *http.Request) *Route {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	for _, r := range rs.routes {
+		if r.matches(req) && r.claim() {
+			return r
+		}
+	}
+	return nil
+}
+
+func (rs *routes) String() string {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	names := make([]string, len(rs.routes))
+	for i, r := range rs.routes {
+		names[i] = r.String()
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
@@ -0,0 +1,26 @@
+package httpx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fatalT records the failures of a T instead of stopping the test.
+type fatalT struct {
+	*testing.T
+	fatals []string
+}
+
+func (t *fatalT) Fatalf(format string, args ...interface{}) {
+	t.fatals = append(t.fatals, fmt.Sprintf(format, args...))
+}
+
+func TestReplyJSONFailsTestOnEncodingError(t *testing.T) {
+	ft := &fatalT{T: t}
+	st := NewStubTransport(ft)
+	st.On("GET", "/users").ReplyJSON(200, map[string]interface{}{"bad": make(chan int)})
+	if len(ft.fatals) != 1 || !strings.Contains(ft.fatals[0], "failed to encode reply for GET /users as JSON") {
+		t.Errorf("failed with %q", ft.fatals)
+	}
+}
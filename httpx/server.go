@@ -0,0 +1,67 @@
+package httpx
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// Server is an httptest.Server replying from stubbed routes.
+type Server struct {
+	*httptest.Server
+	t        T
+	routes   routes
+	mu       sync.Mutex
+	requests []*Request
+}
+
+// Starts a server reporting unmatched requests through t.  Close it when
+// the test is done.
+func NewServer(t T) *Server {
+	s := &Server{t: t, routes: routes{t: t}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Starts a server serving HTTPS with the TLS config, in place of
+// httptest's built-in certificate.
+func NewTLSServer(t T, config *tls.Config) *Server {
+	s := &Server{t: t, routes: routes{t: t}}
+	s.Server = httptest.NewUnstartedServer(http.HandlerFunc(s.serve))
+	s.Server.TLS = config
+	s.Server.StartTLS()
+	return s
+}
+
+// Stubs a reply for requests with the method and path.  Routes are tried in
+// the order they were stubbed.
+func (s *Server) On(method, path string) *Route {
+	return s.routes.add(method, path)
+}
+
+// Returns every request the server received, in order.
+func (s *Server) Requests() []*Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Request{}, s.requests...)
+}
+
+func (s *Server) serve(w http.ResponseWriter, req *http.Request) {
+	recorded, err := recordRequest(req)
+	if err != nil {
+		s.t.Errorf("failed to read body of request %s %s: %s", req.Method, req.URL, err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, recorded)
+	s.mu.Unlock()
+	route := s.routes.find(req)
+	if route == nil {
+		s.t.Errorf("HTTP server received unexpected request %s %s; stubbed routes are %s", req.Method, req.URL, s.routes.String())
+		http.NotFound(w, req)
+		return
+	}
+	route.serve(w, req)
+}
@@ -0,0 +1,181 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sbernheim/goonit/clock"
+)
+
+// SSEEvent is one server-sent event.  Empty fields are left out of the
+// stream.
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  string
+	Retry time.Duration
+}
+
+func (e SSEEvent) format() string {
+	b := &strings.Builder{}
+	if e.ID != "" {
+		fmt.Fprintf(b, "id: %s\n", e.ID)
+	}
+	if e.Event != "" {
+		fmt.Fprintf(b, "event: %s\n", e.Event)
+	}
+	if e.Retry > 0 {
+		fmt.Fprintf(b, "retry: %d\n", e.Retry.Milliseconds())
+	}
+	for _, line := range strings.Split(e.Data, "\n") {
+		fmt.Fprintf(b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+type sseStep struct {
+	event *SSEEvent
+	raw   string
+	delay time.Duration
+}
+
+// SSEServer streams scripted server-sent events.  Each connection gets the
+// next stream in the script, and the last stream once they run out, so a
+// client's reconnect and resume logic can be tested.
+type SSEServer struct {
+	*httptest.Server
+	t            T
+	clock        clock.Clock
+	mu           sync.Mutex
+	streams      [][]sseStep
+	connections  int
+	lastEventIDs []string
+}
+
+// Starts an SSE server reporting failures through t.  Close it when the
+// test is done.
+func NewSSEServer(t T) *SSEServer {
+	s := &SSEServer{t: t, clock: clock.Real(), streams: [][]sseStep{{}}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Times delays with the clock, such as a test's fake clock, so the test
+// decides when delayed events are sent.
+func (s *SSEServer) UseClock(c clock.Clock) *SSEServer {
+	s.mu.Lock()
+	s.clock = c
+	s.mu.Unlock()
+	return s
+}
+
+func (s *SSEServer) add(step sseStep) *SSEServer {
+	s.mu.Lock()
+	last := len(s.streams) - 1
+	s.streams[last] = append(s.streams[last], step)
+	s.mu.Unlock()
+	return s
+}
+
+// Adds a data-only event to the current stream.
+func (s *SSEServer) Data(data string) *SSEServer {
+	return s.Send(SSEEvent{Data: data})
+}
+
+// Adds a named event to the current stream.
+func (s *SSEServer) Event(event, data string) *SSEServer {
+	return s.Send(SSEEvent{Event: event, Data: data})
+}
+
+func (s *SSEServer) Send(e SSEEvent) *SSEServer {
+	return s.add(sseStep{event: &e})
+}
+
+// Adds text written to the stream as is, for testing how clients parse
+// malformed or unusual streams.
+func (s *SSEServer) Raw(text string) *SSEServer {
+	return s.add(sseStep{raw: text})
+}
+
+// Adds a pause before the next event in the current stream.
+func (s *SSEServer) Wait(d time.Duration) *SSEServer {
+	return s.add(sseStep{delay: d})
+}
+
+// Starts the stream for the next connection.  The current stream ends,
+// closing its connection, after its last event.
+func (s *SSEServer) NextStream() *SSEServer {
+	s.mu.Lock()
+	s.streams = append(s.streams, []sseStep{})
+	s.mu.Unlock()
+	return s
+}
+
+// Returns how many connections the server accepted.
+func (s *SSEServer) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+// Returns the Last-Event-ID header of each connection, "" for those
+// without one, in order.
+func (s *SSEServer) LastEventIDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.lastEventIDs...)
+}
+
+// Closes every connection, ending the streams that stay open, and then the
+// server, which would otherwise wait for connected clients to hang up.
+func (s *SSEServer) Close() {
+	s.CloseClientConnections()
+	s.Server.Close()
+}
+
+func (s *SSEServer) serve(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.t.Errorf("SSE server cannot flush responses")
+		return
+	}
+	s.mu.Lock()
+	stream := s.streams[len(s.streams)-1]
+	if s.connections < len(s.streams) {
+		stream = s.streams[s.connections]
+	}
+	stream = append([]sseStep{}, stream...)
+	s.connections++
+	s.lastEventIDs = append(s.lastEventIDs, req.Header.Get("Last-Event-ID"))
+	c := s.clock
+	last := s.connections >= len(s.streams)
+	s.mu.Unlock()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for _, step := range stream {
+		switch {
+		case step.delay > 0:
+			select {
+			case <-c.After(step.delay):
+			case <-req.Context().Done():
+				return
+			}
+		case step.event != nil:
+			fmt.Fprint(w, step.event.format())
+		default:
+			fmt.Fprint(w, step.raw)
+		}
+		flusher.Flush()
+	}
+	if last {
+		// The last stream stays open, as a live server's would.
+		<-req.Context().Done()
+	}
+}
@@ -0,0 +1,34 @@
+package httpx
+
+import (
+	"bufio"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSSEServerCloseEndsOpenStream(t *testing.T) {
+	s := NewSSEServer(t).Data("hello")
+	resp, err := http.Get(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	r := bufio.NewReader(resp.Body)
+	line, err := r.ReadString('\n')
+	if err != nil || strings.TrimSpace(line) != "data: hello" {
+		t.Fatalf("read %q, %v", line, err)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		s.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked on the open stream")
+	}
+}
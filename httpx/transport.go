@@ -0,0 +1,70 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// StubTransport is an http.RoundTripper that replies from stubbed routes
+// without opening sockets.  Requests no route matches fail the test and
+// return an error to the client.
+type StubTransport struct {
+	t        T
+	routes   routes
+	mu       sync.Mutex
+	requests []*Request
+}
+
+func NewStubTransport(t T) *StubTransport {
+	return &StubTransport{t: t, routes: routes{t: t}}
+}
+
+// Stubs a reply for requests with the method and path, to any host.
+func (st *StubTransport) On(method, path string) *Route {
+	return st.routes.add(method, path)
+}
+
+// Returns every request the transport received, in order.
+func (st *StubTransport) Requests() []*Request {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return append([]*Request{}, st.requests...)
+}
+
+// Serves the request from the stubbed routes.  As the http.RoundTripper
+// contract asks, the request is left as it was, apart from its body being
+// read and closed; routes are served a clone of it.
+func (st *StubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	served := req.Clone(req.Context())
+	recorded, err := recordRequest(served)
+	if err != nil {
+		return nil, err
+	}
+	st.mu.Lock()
+	st.requests = append(st.requests, recorded)
+	st.mu.Unlock()
+	route := st.routes.find(served)
+	if route == nil {
+		st.t.Errorf("HTTP client sent unexpected request %s %s; stubbed routes are %s", req.Method, req.URL, st.routes.String())
+		return nil, fmt.Errorf("httpx: no stubbed route for %s %s", req.Method, req.URL)
+	}
+	rec := httptest.NewRecorder()
+	route.serve(rec, served)
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// StubClient is an http.Client whose requests are answered by a
+// StubTransport.
+type StubClient struct {
+	*http.Client
+	*StubTransport
+}
+
+func NewStubClient(t T) *StubClient {
+	st := NewStubTransport(t)
+	return &StubClient{Client: &http.Client{Transport: st}, StubTransport: st}
+}
@@ -0,0 +1,31 @@
+package httpx
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStubTransportLeavesRequestAlone(t *testing.T) {
+	st := NewStubTransport(t)
+	route := st.On("POST", "/users").Reply(201, "created")
+	body := ioutil.NopCloser(strings.NewReader(`{"name":"ann"}`))
+	req, err := http.NewRequest("POST", "http://api.test/users", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := st.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Body != body {
+		t.Errorf("RoundTrip replaced the caller's request body")
+	}
+	if data, _ := ioutil.ReadAll(resp.Body); resp.StatusCode != 201 || string(data) != "created" || route.Hits() != 1 {
+		t.Errorf("replied %d %q", resp.StatusCode, data)
+	}
+	if recorded := st.Requests(); len(recorded) != 1 || string(recorded[0].Body) != `{"name":"ann"}` {
+		t.Errorf("recorded %v", recorded)
+	}
+}
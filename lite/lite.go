@@ -0,0 +1,170 @@
+// Package lite is a minimal goonit test base without the Gomega dependency,
+// for lightweight test binaries that want goonit's temp directory, env,
+// args, capture and mock helpers but not the full matcher stack.
+//
+// Its assertions use reflect.DeepEqual and stop the test on failure.
+package lite
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sbernheim/goonit/mock"
+)
+
+type Test struct {
+	t            *testing.T
+	mockProvider mock.Provider
+	captured     []interface{}
+	afterFunc    func()
+}
+
+func New(t *testing.T) *Test {
+	mockProvider := mock.NewProvider(t)
+	return &Test{
+		t:            t,
+		mockProvider: mockProvider,
+		captured:     []interface{}{},
+		afterFunc:    func() { mockProvider.Finish() },
+	}
+}
+
+func (x *Test) Logf(format string, args ...interface{}) {
+	x.t.Logf(format, args...)
+}
+
+func (x *Test) Fatalf(format string, args ...interface{}) {
+	x.t.Helper()
+	x.t.Fatalf(format, args...)
+}
+
+func (x *Test) Mock() mock.Provider {
+	return x.mockProvider
+}
+
+func (x *Test) DoAfter(doAfterFunc func()) {
+	f := x.afterFunc
+	x.afterFunc = func() {
+		f()
+		doAfterFunc()
+	}
+}
+
+// Sets the environment variable until the test ends.
+func (x *Test) SetEnv(name, val string) *Test {
+	x.t.Setenv(name, val)
+	return x
+}
+
+// Replaces os.Args until the test is Done.
+func (x *Test) SetArgs(args ...string) *Test {
+	currentArgs := os.Args
+	x.DoAfter(func() {
+		os.Args = currentArgs
+	})
+	os.Args = append([]string{}, args...)
+	return x
+}
+
+func (x *Test) TempDir() string {
+	return x.t.TempDir()
+}
+
+func (x *Test) TempPath(filename string) string {
+	return filepath.Join(x.TempDir(), filename)
+}
+
+func (x *Test) ErrFor(errFor string) error {
+	return fmt.Errorf("this is a test-generated error for '%s'", errFor)
+}
+
+func (x *Test) Capture(captured ...interface{}) *Test {
+	x.captured = append(x.captured, captured...)
+	return x
+}
+
+func (x *Test) AllCaptured() []interface{} {
+	return x.captured
+}
+
+func (x *Test) Captured(index int) interface{} {
+	x.t.Helper()
+	if index >= len(x.captured) {
+		x.t.Fatalf("there were only %d captured parameter values - cannot retrieve index %d", len(x.captured), index)
+	}
+	return x.captured[index]
+}
+
+func (x *Test) Done() {
+	x.afterFunc()
+}
+
+func (x *Test) fail(msgAndArgs []interface{}, format string, args ...interface{}) {
+	x.t.Helper()
+	message := fmt.Sprintf(format, args...)
+	if len(msgAndArgs) > 0 {
+		if f, ok := msgAndArgs[0].(string); ok {
+			message = fmt.Sprintf(f, msgAndArgs[1:]...) + ": " + message
+		}
+	}
+	x.t.Fatal(message)
+}
+
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// Each assertion takes an optional format string and arguments describing it.
+
+func (x *Test) Equal(actual, expected interface{}, msgAndArgs ...interface{}) {
+	x.t.Helper()
+	if !reflect.DeepEqual(actual, expected) {
+		x.fail(msgAndArgs, "expected\n\t%#v\nto equal\n\t%#v", actual, expected)
+	}
+}
+
+func (x *Test) True(actual bool, msgAndArgs ...interface{}) {
+	x.t.Helper()
+	if !actual {
+		x.fail(msgAndArgs, "expected true")
+	}
+}
+
+func (x *Test) Nil(actual interface{}, msgAndArgs ...interface{}) {
+	x.t.Helper()
+	if !isNil(actual) {
+		x.fail(msgAndArgs, "expected nil but got %#v", actual)
+	}
+}
+
+func (x *Test) NotNil(actual interface{}, msgAndArgs ...interface{}) {
+	x.t.Helper()
+	if isNil(actual) {
+		x.fail(msgAndArgs, "expected a value but got nil")
+	}
+}
+
+func (x *Test) NoError(err error, msgAndArgs ...interface{}) {
+	x.t.Helper()
+	if err != nil {
+		x.fail(msgAndArgs, "unexpected error: %s", err.Error())
+	}
+}
+
+func (x *Test) Error(err error, msgAndArgs ...interface{}) {
+	x.t.Helper()
+	if err == nil {
+		x.fail(msgAndArgs, "expected an error")
+	}
+}
@@ -0,0 +1,69 @@
+package match
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/golang/mock/gomock"
+)
+
+type errorIs struct{ target error }
+
+// Matches an error parameter with the target in its chain, as reported
+// by errors.Is.
+func ErrorIs(target error) gomock.Matcher {
+	return &errorIs{target}
+}
+
+func (m *errorIs) Matches(param interface{}) bool {
+	err, ok := param.(error)
+	return ok && errors.Is(err, m.target)
+}
+
+func (m *errorIs) String() string {
+	return fmt.Sprintf("is an error wrapping %v", m.target)
+}
+
+type errorAs struct{ target reflect.Type }
+
+// Matches an error parameter with an error of the same type as the
+// example in its chain, as reported by errors.As.  Panics if the example
+// is nil, since it then has no type to match.
+func ErrorAs(example error) gomock.Matcher {
+	if example == nil {
+		panic("match.ErrorAs needs a non-nil example error, such as &fs.PathError{}, to match the type of")
+	}
+	return &errorAs{reflect.TypeOf(example)}
+}
+
+func (m *errorAs) Matches(param interface{}) bool {
+	err, ok := param.(error)
+	if !ok {
+		return false
+	}
+	target := reflect.New(m.target)
+	return errors.As(err, target.Interface())
+}
+
+func (m *errorAs) String() string {
+	return fmt.Sprintf("is an error wrapping a %s", m.target)
+}
+
+type errorContains struct{ substr string }
+
+// Matches an error parameter whose message, which includes the messages of
+// the errors it wraps, contains the substring.
+func ErrorContains(substr string) gomock.Matcher {
+	return &errorContains{substr}
+}
+
+func (m *errorContains) Matches(param interface{}) bool {
+	err, ok := param.(error)
+	return ok && err != nil && strings.Contains(err.Error(), m.substr)
+}
+
+func (m *errorContains) String() string {
+	return fmt.Sprintf("is an error containing %q", m.substr)
+}
@@ -0,0 +1,27 @@
+package match
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestErrorAsMatchesWrappedErrorOfExampleType(t *testing.T) {
+	m := ErrorAs(&fs.PathError{})
+	if !m.Matches(fmt.Errorf("loading: %w", &fs.PathError{Op: "open"})) {
+		t.Errorf("did not match a wrapped *fs.PathError")
+	}
+	if m.Matches(fmt.Errorf("loading")) {
+		t.Errorf("matched an error without a *fs.PathError")
+	}
+}
+
+func TestErrorAsRejectsNilExample(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "non-nil example") {
+			t.Errorf("recovered %v, expected a panic about the nil example", r)
+		}
+	}()
+	ErrorAs(nil)
+}
@@ -0,0 +1,63 @@
+package match
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/golang/mock/gomock"
+)
+
+var hashes = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// Returns a new hash for the named algorithm: md5, sha1, sha256 or sha512.
+func NewHash(alg string) (hash.Hash, error) {
+	newHash, found := hashes[strings.ToLower(alg)]
+	if !found {
+		return nil, fmt.Errorf("unsupported hash algorithm '%s'", alg)
+	}
+	return newHash(), nil
+}
+
+type hashOfContent struct {
+	alg string
+	hex string
+}
+
+// Matches a string or []byte parameter whose hash, using the named algorithm,
+// equals the hex-encoded digest.
+func HashOfContent(alg, hexDigest string) gomock.Matcher {
+	return &hashOfContent{alg: alg, hex: strings.ToLower(hexDigest)}
+}
+
+func (m *hashOfContent) Matches(param interface{}) bool {
+	var data []byte
+	switch p := param.(type) {
+	case []byte:
+		data = p
+	case string:
+		data = []byte(p)
+	default:
+		return false
+	}
+	h, err := NewHash(m.alg)
+	if err != nil {
+		return false
+	}
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)) == m.hex
+}
+
+func (m *hashOfContent) String() string {
+	return fmt.Sprintf("has %s hash %s", m.alg, m.hex)
+}
@@ -0,0 +1,52 @@
+package match
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/golang/mock/gomock"
+)
+
+type jsonEq struct {
+	expected string
+	value    interface{}
+	err      error
+}
+
+// Matches a string, []byte or json.RawMessage parameter holding JSON that
+// is semantically equal to the expected JSON, ignoring key order and spacing.
+func JSONEq(expected string) gomock.Matcher {
+	m := &jsonEq{expected: expected}
+	m.err = json.Unmarshal([]byte(expected), &m.value)
+	return m
+}
+
+func (m *jsonEq) Matches(param interface{}) bool {
+	if m.err != nil {
+		return false
+	}
+	var data []byte
+	switch p := param.(type) {
+	case []byte:
+		data = p
+	case json.RawMessage:
+		data = p
+	case string:
+		data = []byte(p)
+	default:
+		return false
+	}
+	var actual interface{}
+	if err := json.Unmarshal(data, &actual); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(actual, m.value)
+}
+
+func (m *jsonEq) String() string {
+	if m.err != nil {
+		return fmt.Sprintf("is JSON equal to invalid JSON %s (%s)", m.expected, m.err.Error())
+	}
+	return fmt.Sprintf("is JSON equal to %s", m.expected)
+}
@@ -0,0 +1,26 @@
+package match
+
+import (
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+type protoEqual struct{ expected proto.Message }
+
+// Matches a proto.Message parameter equal to the expected message as
+// reported by proto.Equal, which compares fields rather than pointers.
+func ProtoEqual(expected proto.Message) gomock.Matcher {
+	return &protoEqual{expected}
+}
+
+func (m *protoEqual) Matches(param interface{}) bool {
+	actual, ok := param.(proto.Message)
+	return ok && proto.Equal(actual, m.expected)
+}
+
+func (m *protoEqual) String() string {
+	return fmt.Sprintf("is proto equal to {%s}", prototext.MarshalOptions{}.Format(m.expected))
+}
@@ -0,0 +1,40 @@
+package match
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/mock/gomock"
+)
+
+type timeWithin struct {
+	expected  time.Time
+	tolerance time.Duration
+}
+
+// Matches a time.Time or *time.Time parameter no more than the tolerance
+// before or after the expected time.
+func TimeWithin(expected time.Time, tolerance time.Duration) gomock.Matcher {
+	return &timeWithin{expected, tolerance}
+}
+
+func (m *timeWithin) Matches(param interface{}) bool {
+	var actual time.Time
+	switch p := param.(type) {
+	case time.Time:
+		actual = p
+	case *time.Time:
+		if p == nil {
+			return false
+		}
+		actual = *p
+	default:
+		return false
+	}
+	diff := actual.Sub(m.expected)
+	return diff <= m.tolerance && diff >= -m.tolerance
+}
+
+func (m *timeWithin) String() string {
+	return fmt.Sprintf("is within %s of %s", m.tolerance, m.expected.Format(time.RFC3339Nano))
+}
@@ -0,0 +1,36 @@
+package mock
+
+import "fmt"
+
+// Returns the BaseProvider that p is or embeds, for building another
+// provider on the same one.  Providers built on the same BaseProvider share
+// its controller and mocks, so a test can layer mock catalogs, such as a
+// shared org-wide provider and a service-specific one, and verify them all
+// with a single Finish:
+//
+//	type Provider struct {
+//		*mock.BaseProvider
+//		*orgmocks.OrgProvider
+//		*svcmocks.SvcProvider
+//	}
+//
+//	func NewProvider(t *testing.T) *Provider {
+//		base := mock.Base(mock.NewProvider(t))
+//		return &Provider{base, orgmocks.Extend(base), svcmocks.Extend(base)}
+//	}
+//
+// where the catalogs were generated with goonit-gen -type=OrgProvider and
+// -type=SvcProvider.  Embedding the BaseProvider directly keeps its
+// methods, Finish among them, from being ambiguous between the catalogs
+// embedding it too.
+func Base(p Provider) *BaseProvider {
+	b, ok := p.(interface{ base() *BaseProvider })
+	if !ok {
+		panic(fmt.Sprintf("provider %T neither is nor embeds a *mock.BaseProvider", p))
+	}
+	return b.base()
+}
+
+func (p *BaseProvider) base() *BaseProvider {
+	return p
+}
@@ -2,8 +2,11 @@
 //
 // It uses Gomock under the hood. See: https://github.com/golang/mock
 //
-// Extend Provider and BaseProvider to add constructor methods that return whatever mocks your tests
-// need and store mock instances in your implementation of the Test interface.
+// Extend Provider and BaseProvider to add accessor methods that return whatever mocks your tests
+// need.  Build accessors on BaseProvider.Lazy so each returns the same mock instance every time it is
+// called, and expectations set on it apply to the instance injected into the code under test.
+// The goonit-gen command in cmd/goonit-gen generates such an extension for a package's interfaces.
+// Build providers on each other with Base to share one controller.
 //
 package mock
 
@@ -11,13 +14,28 @@ package mock
 // MockLogger below.
 //
 //go:generate mockgen -destination=mockLogger.go -package=mock github.com/go-logr/logr Logger
+//go:generate mockgen -destination=mockRoundTripper.go -package=mock net/http RoundTripper
+//go:generate mockgen -destination=mockIO.go -package=mock io Reader,Writer,ReadWriteCloser,ReaderAt
+//go:generate mockgen -destination=mockFS.go -package=mock io/fs FS,File
+//go:generate mockgen -destination=mockConn.go -package=mock net Conn
 
 import (
+	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	gomock "github.com/golang/mock/gomock"
+
+	"github.com/sbernheim/goonit/clock"
 )
 
+// Provider is what a test needs of a mock provider.  The accessors for the
+// standard library mocks, the fake clock, Register, Get and Reset are on
+// BaseProvider, so providers written from scratch need only these; get at
+// them from a provider built on one with Base:
+//
+//	rt := mock.Base(x.Mock()).RoundTripper()
 type Provider interface {
 	Controller() *gomock.Controller
 	Logger() *MockLogger
@@ -25,25 +43,265 @@ type Provider interface {
 }
 
 type BaseProvider struct {
-	t *testing.T
-	c *gomock.Controller
+	t        gomock.TestHelper
+	reporter gomock.TestReporter
+	c        *gomock.Controller
+	mu       sync.Mutex
+	mocks    map[string]interface{}
+	ctors    map[string]func(c *gomock.Controller) interface{}
+}
+
+// Option configures a provider.
+type Option func(o *options)
+
+type options struct {
+	reporter gomock.TestReporter
+}
+
+// Reports failures from the controller, such as unexpected or missing
+// calls, through r in place of the test.
+func WithReporter(r gomock.TestReporter) Option {
+	return func(o *options) {
+		o.reporter = r
+	}
+}
+
+func NewProvider(t *testing.T, opts ...Option) Provider {
+	return NewProviderFor(t, opts...)
 }
 
-func NewProvider(t *testing.T) Provider {
+// Creates a provider whose controller reports to any gomock.TestHelper,
+// such as Ginkgo's GinkgoT(), in place of a *testing.T.
+func NewProviderFor(t gomock.TestHelper, opts ...Option) Provider {
+	o := &options{reporter: t}
+	for _, opt := range opts {
+		opt(o)
+	}
 	return &BaseProvider{
-		t: t,
-		c: gomock.NewController(t),
+		t:        t,
+		reporter: o.reporter,
+		c:        gomock.NewController(o.reporter),
+		mocks:    map[string]interface{}{},
+		ctors:    map[string]func(c *gomock.Controller) interface{}{},
 	}
 }
 
 func (p *BaseProvider) Controller() *gomock.Controller {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	return p.c
 }
 
+// Returns the mock stored under the name, calling newMock to create it
+// with the provider's controller on first use.
+func (p *BaseProvider) Lazy(name string, newMock func(c *gomock.Controller) interface{}) interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	m, found := p.mocks[name]
+	if !found {
+		m = newMock(p.c)
+		p.mocks[name] = m
+	}
+	return m
+}
+
+// Registers the constructor Get creates the mock stored under the name
+// with, typically the mock's generated constructor:
+//
+//	p.Register("Repo", func(c *gomock.Controller) interface{} { return NewMockRepo(c) })
+//
+// Registering a name again replaces its constructor and any mock already
+// created with it.  Registered mocks are kept apart from the ones the
+// accessors return, so registering a name such as "Logger" doesn't replace
+// the provider's Logger.
+func (p *BaseProvider) Register(name string, newMock func(c *gomock.Controller) interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.ctors[name] = newMock
+	delete(p.mocks, registeredKey(name))
+}
+
+// Returns the key the mock registered under the name is stored under.
+// Lazy keys are interface names, so the prefix keeps the two apart.
+func registeredKey(name string) string {
+	return "registered:" + name
+}
+
+// Returns the mock registered under the name, the same instance on every
+// call, creating it on first use.  Fails the test if no mock is registered
+// under the name.
+func (p *BaseProvider) Get(name string) interface{} {
+	p.mu.Lock()
+	newMock, found := p.ctors[name]
+	p.mu.Unlock()
+	if !found {
+		p.t.Helper()
+		p.t.Fatalf("no mock registered as '%s'; register it with Register first", name)
+		return nil
+	}
+	return p.Lazy(registeredKey(name), newMock)
+}
+
+// Returns the mock registered under the name as a T, the type the
+// constructor returns, failing the test if it is another type:
+//
+//	repo := mock.Get[*MockRepo](x.Mock(), "Repo")
+func Get[T any](p Provider, name string) T {
+	base := Base(p)
+	m := base.Get(name)
+	typed, ok := m.(T)
+	if !ok && m != nil {
+		base.t.Helper()
+		base.t.Fatalf("mock registered as '%s' is a %T, not a %s", name, m, reflect.TypeOf((*T)(nil)).Elem())
+	}
+	return typed
+}
+
+// Returns the provider's MockLogger, the same instance on every call.
 func (p *BaseProvider) Logger() *MockLogger {
-	return NewMockLogger(p.c)
+	return p.Lazy("Logger", func(c *gomock.Controller) interface{} {
+		return NewMockLogger(c)
+	}).(*MockLogger)
+}
+
+// Returns a new MockLogger, for tests that need more than one logger.
+func (p *BaseProvider) NewLogger() *MockLogger {
+	return NewMockLogger(p.Controller())
+}
+
+// Returns the provider's MockRoundTripper, the same instance on every call.
+// Use it as an http.Client's Transport to expect individual requests.
+func (p *BaseProvider) RoundTripper() *MockRoundTripper {
+	return p.Lazy("RoundTripper", func(c *gomock.Controller) interface{} {
+		return NewMockRoundTripper(c)
+	}).(*MockRoundTripper)
+}
+
+// Returns a new MockRoundTripper, for tests that need more than one.
+func (p *BaseProvider) NewRoundTripper() *MockRoundTripper {
+	return NewMockRoundTripper(p.Controller())
+}
+
+// The accessors for mocks of standard library interfaces below each return
+// the same instance on every call, and their New counterparts a new one,
+// as for Logger and NewLogger.
+
+func (p *BaseProvider) Reader() *MockReader {
+	return p.Lazy("Reader", func(c *gomock.Controller) interface{} {
+		return NewMockReader(c)
+	}).(*MockReader)
+}
+
+func (p *BaseProvider) NewReader() *MockReader {
+	return NewMockReader(p.Controller())
+}
+
+func (p *BaseProvider) Writer() *MockWriter {
+	return p.Lazy("Writer", func(c *gomock.Controller) interface{} {
+		return NewMockWriter(c)
+	}).(*MockWriter)
+}
+
+func (p *BaseProvider) NewWriter() *MockWriter {
+	return NewMockWriter(p.Controller())
+}
+
+func (p *BaseProvider) ReadWriteCloser() *MockReadWriteCloser {
+	return p.Lazy("ReadWriteCloser", func(c *gomock.Controller) interface{} {
+		return NewMockReadWriteCloser(c)
+	}).(*MockReadWriteCloser)
+}
+
+func (p *BaseProvider) NewReadWriteCloser() *MockReadWriteCloser {
+	return NewMockReadWriteCloser(p.Controller())
+}
+
+func (p *BaseProvider) ReaderAt() *MockReaderAt {
+	return p.Lazy("ReaderAt", func(c *gomock.Controller) interface{} {
+		return NewMockReaderAt(c)
+	}).(*MockReaderAt)
+}
+
+func (p *BaseProvider) NewReaderAt() *MockReaderAt {
+	return NewMockReaderAt(p.Controller())
+}
+
+// Returns the provider's MockFS, an fs.FS whose Open can return the
+// provider's File.
+func (p *BaseProvider) FS() *MockFS {
+	return p.Lazy("FS", func(c *gomock.Controller) interface{} {
+		return NewMockFS(c)
+	}).(*MockFS)
+}
+
+func (p *BaseProvider) NewFS() *MockFS {
+	return NewMockFS(p.Controller())
+}
+
+func (p *BaseProvider) File() *MockFile {
+	return p.Lazy("File", func(c *gomock.Controller) interface{} {
+		return NewMockFile(c)
+	}).(*MockFile)
+}
+
+func (p *BaseProvider) NewFile() *MockFile {
+	return NewMockFile(p.Controller())
+}
+
+// Returns the provider's MockConn, a net.Conn.
+func (p *BaseProvider) Conn() *MockConn {
+	return p.Lazy("Conn", func(c *gomock.Controller) interface{} {
+		return NewMockConn(c)
+	}).(*MockConn)
+}
+
+func (p *BaseProvider) NewConn() *MockConn {
+	return NewMockConn(p.Controller())
+}
+
+// Returns the provider's fake clock, started at the current time on first use.
+func (p *BaseProvider) Clock() *clock.Fake {
+	return p.Lazy("Clock", func(c *gomock.Controller) interface{} {
+		return clock.NewFake(time.Now())
+	}).(*clock.Fake)
+}
+
+// Finishes the controller, verifying its expectations, and replaces it with
+// a new one, so a table-driven test can reuse the provider for each row
+// without expectations leaking between rows.  Registered constructors are
+// kept, as is everything not bound to the controller, such as the Clock.
+//
+// The gomock mocks it created are dropped, so accessors and Get create new
+// ones with the new controller on next use.  Anything built from the old
+// mocks, such as a client given the old Logger or RoundTripper, still calls
+// them and fails the test as soon as it does: build it again, from the new
+// mocks, for each row.
+func (p *BaseProvider) Reset() {
+	p.t.Helper()
+	p.Controller().Finish()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.c = gomock.NewController(p.reporter)
+	for name, m := range p.mocks {
+		if usesController(m) {
+			delete(p.mocks, name)
+		}
+	}
+}
+
+var controllerType = reflect.TypeOf((*gomock.Controller)(nil))
+
+// Reports whether m is a mock generated by mockgen, which keeps its
+// controller in a ctrl field.
+func usesController(m interface{}) bool {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	field, found := v.Elem().Type().FieldByName("ctrl")
+	return found && field.Type == controllerType
 }
 
 func (p *BaseProvider) Finish() {
-	p.c.Finish()
+	p.Controller().Finish()
 }
@@ -0,0 +1,57 @@
+package mock
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+func TestResetKeepsClockAndReplacesMocks(t *testing.T) {
+	p := Base(NewProvider(t))
+	clk := p.Clock()
+	logger := p.Logger()
+	p.Reset()
+	if p.Clock() != clk {
+		t.Errorf("Reset replaced the fake clock")
+	}
+	if p.Logger() == logger {
+		t.Errorf("Reset kept the mock logger bound to the finished controller")
+	}
+}
+
+func TestRegisterKeepsAccessorMocks(t *testing.T) {
+	p := Base(NewProvider(t))
+	logger := p.Logger()
+	p.Register("Logger", func(c *gomock.Controller) interface{} { return NewMockReader(c) })
+	if p.Logger() != logger {
+		t.Errorf("Register replaced the provider's Logger")
+	}
+	if _, ok := p.Get("Logger").(*MockReader); !ok {
+		t.Errorf("Get did not return the registered mock")
+	}
+}
+
+// fatalT records the failures of a gomock.TestHelper instead of stopping
+// the test.
+type fatalT struct {
+	*testing.T
+	fatals []string
+}
+
+func (t *fatalT) Fatalf(format string, args ...interface{}) {
+	t.fatals = append(t.fatals, fmt.Sprintf(format, args...))
+}
+
+func TestGetFailsTestOnTypeMismatch(t *testing.T) {
+	ft := &fatalT{T: t}
+	p := NewProviderFor(ft)
+	Base(p).Register("Repo", func(c *gomock.Controller) interface{} { return NewMockReader(c) })
+	if w := Get[*MockWriter](p, "Repo"); w != nil {
+		t.Errorf("returned %v", w)
+	}
+	if len(ft.fatals) != 1 || !strings.Contains(ft.fatals[0], "is a *mock.MockReader, not a *mock.MockWriter") {
+		t.Errorf("failed with %q", ft.fatals)
+	}
+}
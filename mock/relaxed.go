@@ -0,0 +1,141 @@
+package mock
+
+import (
+	"fmt"
+	"reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// UnexpectedCall is a call to a relaxed mock that no expectation matched.
+type UnexpectedCall struct {
+	// The mock that was called.
+	Receiver interface{}
+	Mock     string
+	Method   string
+	Args     []interface{}
+}
+
+func (c UnexpectedCall) String() string {
+	return fmt.Sprintf("%s.%s%v", c.Mock, c.Method, c.Args)
+}
+
+// Relaxes the generated mock so calls no expectation matches return zero
+// values and are passed to record instead of failing the test.  Relax adds
+// catch-all expectations behind those already set, and gomock tries
+// expectations in the order they were set, so relax a mock after setting
+// its expectations; ones set later are never reached.
+func Relax(m interface{}, record func(call UnexpectedCall)) {
+	mv := reflect.ValueOf(m)
+	expect := mv.MethodByName("EXPECT")
+	if !expect.IsValid() {
+		panic(fmt.Sprintf("%T is not a generated mock with an EXPECT method", m))
+	}
+	recorder := expect.Call(nil)[0]
+	name := reflect.Indirect(mv).Type().Name()
+	for i := 0; i < recorder.NumMethod(); i++ {
+		method := recorder.Type().Method(i).Name
+		mockMethod := mv.MethodByName(method)
+		if !mockMethod.IsValid() {
+			continue
+		}
+		relaxMethod(recorder.Method(i), mockMethod.Type(), &relaxedCall{receiver: m, mock: name, method: method, record: record})
+	}
+}
+
+type relaxedCall struct {
+	receiver interface{}
+	mock     string
+	method   string
+	record   func(call UnexpectedCall)
+	pending  []interface{}
+}
+
+func (c *relaxedCall) done(args []interface{}) {
+	c.record(UnexpectedCall{Receiver: c.receiver, Mock: c.mock, Method: c.method, Args: args})
+}
+
+func relaxMethod(expect reflect.Value, methodType reflect.Type, c *relaxedCall) {
+	matchers := make([]reflect.Value, methodType.NumIn())
+	if !methodType.IsVariadic() {
+		for i := range matchers {
+			matchers[i] = reflect.ValueOf(gomock.Any())
+		}
+		doAndReturnZeros(expect, methodType, matchers, c)
+		return
+	}
+	// gomock cannot pass nil variadic arguments to DoAndReturn or match them
+	// as a slice, so variadic calls are matched by arity, up to
+	// relaxedVarargs arguments, and recorded by their matchers, which gomock
+	// runs under its lock.  One variadic matcher comes last since it also
+	// matches calls with more arguments, recording only the first.
+	zeros := make([]interface{}, methodType.NumOut())
+	for i := range zeros {
+		zeros[i] = reflect.Zero(methodType.Out(i)).Interface()
+	}
+	fixed := methodType.NumIn() - 1
+	for _, varargs := range append(makeRange(2, relaxedVarargs), 0, 1) {
+		n := fixed + varargs
+		if n == 0 {
+			doAndReturnZeros(expect, methodType, nil, c)
+			continue
+		}
+		matchers := make([]reflect.Value, n)
+		for i := range matchers {
+			matchers[i] = reflect.ValueOf(&recordingMatcher{call: c, index: i, last: i == n-1})
+		}
+		call := expect.Call(matchers)[0].Interface().(*gomock.Call)
+		call.AnyTimes().Return(zeros...)
+	}
+}
+
+// The most variadic arguments a relaxed call is recorded with in full.
+const relaxedVarargs = 16
+
+func makeRange(from, to int) []int {
+	r := []int{}
+	for i := from; i <= to; i++ {
+		r = append(r, i)
+	}
+	return r
+}
+
+func doAndReturnZeros(expect reflect.Value, methodType reflect.Type, matchers []reflect.Value, c *relaxedCall) {
+	call := expect.Call(matchers)[0].Interface().(*gomock.Call)
+	call.AnyTimes().DoAndReturn(reflect.MakeFunc(methodType, func(args []reflect.Value) []reflect.Value {
+		values := make([]interface{}, len(args))
+		for i, arg := range args {
+			values[i] = arg.Interface()
+		}
+		c.done(values)
+		rets := make([]reflect.Value, methodType.NumOut())
+		for i := range rets {
+			rets[i] = reflect.Zero(methodType.Out(i))
+		}
+		return rets
+	}).Interface())
+}
+
+// recordingMatcher matches any argument of a relaxed variadic call,
+// collecting the call's arguments.
+type recordingMatcher struct {
+	call  *relaxedCall
+	index int
+	last  bool
+}
+
+func (m *recordingMatcher) Matches(x interface{}) bool {
+	c := m.call
+	if m.index == 0 {
+		c.pending = nil
+	}
+	c.pending = append(c.pending, x)
+	if m.last {
+		c.done(c.pending)
+	}
+	return true
+}
+
+func (m *recordingMatcher) String() string {
+	return "is anything"
+}
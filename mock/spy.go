@@ -0,0 +1,116 @@
+package mock
+
+import (
+	"fmt"
+	"reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// SpiedCall is a call a spy delegated to the real implementation.
+type SpiedCall struct {
+	// The mock that was called.
+	Receiver interface{}
+	Mock     string
+	Method   string
+	Args     []interface{}
+	Results  []interface{}
+}
+
+func (c SpiedCall) String() string {
+	return fmt.Sprintf("%s.%s%v = %v", c.Mock, c.Method, c.Args, c.Results)
+}
+
+// SpyRecorder records the calls of spies, as *core.BaseTest does in its
+// Capture subsystem.
+type SpyRecorder interface {
+	RecordSpiedCall(call SpiedCall)
+}
+
+// Makes the generated mock m a spy on real: every call no expectation
+// already set on m matches is delegated to real, as for Delegate, and
+// recorded with rec.
+// Returns m as a T:
+//
+//	repo := mock.Spy[Repo](mock.Get[*MockRepo](x.Mock(), "Repo"), realRepo, x)
+//
+// gomock cannot pass nil variadic arguments after the first to a delegate,
+// so spy on variadic methods only with non-nil arguments.
+func Spy[T any](m T, real T, rec SpyRecorder) T {
+	mv := reflect.ValueOf(m)
+	name := reflect.Indirect(mv).Type().Name()
+	delegate(m, real, func(method string, args, results []interface{}) {
+		rec.RecordSpiedCall(SpiedCall{Receiver: m, Mock: name, Method: method, Args: args, Results: results})
+	})
+	return m
+}
+
+// Makes the generated mock m a partial mock of real: calls matching the
+// expectations already set on m are stubbed, and every other call is
+// forwarded to real.  Returns m as a T:
+//
+//	repo.EXPECT().Delete(id).Return(errNotFound)
+//	partial := mock.Delegate[Repo](repo, realRepo)
+//
+// gomock tries expectations in the order they were set, so set them
+// before delegating; ones set later are never reached.
+func Delegate[T any](m T, real T) T {
+	delegate(m, real, nil)
+	return m
+}
+
+// Adds a catch-all expectation for every method of the generated mock that
+// calls the same method of real, passing each call to onCall if it isn't
+// nil.
+func delegate(m interface{}, real interface{}, onCall func(method string, args, results []interface{})) {
+	mv, rv := reflect.ValueOf(m), reflect.ValueOf(real)
+	expect := mv.MethodByName("EXPECT")
+	if !expect.IsValid() {
+		panic(fmt.Sprintf("%T is not a generated mock with an EXPECT method", m))
+	}
+	recorder := expect.Call(nil)[0]
+	for i := 0; i < recorder.NumMethod(); i++ {
+		method := recorder.Type().Method(i).Name
+		mockMethod, realMethod := mv.MethodByName(method), rv.MethodByName(method)
+		if !mockMethod.IsValid() {
+			continue
+		}
+		if !realMethod.IsValid() {
+			panic(fmt.Sprintf("%T has no method %s to delegate %T.%s to", real, method, m, method))
+		}
+		methodType := mockMethod.Type()
+		matchers := make([]reflect.Value, methodType.NumIn())
+		for j := range matchers {
+			matchers[j] = reflect.ValueOf(gomock.Any())
+		}
+		call := recorder.Method(i).Call(matchers)[0].Interface().(*gomock.Call)
+		call.AnyTimes().DoAndReturn(reflect.MakeFunc(methodType, func(args []reflect.Value) []reflect.Value {
+			var results []reflect.Value
+			if methodType.IsVariadic() {
+				results = realMethod.CallSlice(args)
+			} else {
+				results = realMethod.Call(args)
+			}
+			if onCall != nil {
+				onCall(method, values(args, methodType.IsVariadic()), values(results, false))
+			}
+			return results
+		}).Interface())
+	}
+}
+
+// Returns the values as interfaces, spreading out a trailing variadic
+// slice.
+func values(vs []reflect.Value, variadic bool) []interface{} {
+	out := []interface{}{}
+	for i, v := range vs {
+		if variadic && i == len(vs)-1 {
+			for j := 0; j < v.Len(); j++ {
+				out = append(out, v.Index(j).Interface())
+			}
+			continue
+		}
+		out = append(out, v.Interface())
+	}
+	return out
+}
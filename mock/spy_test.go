@@ -0,0 +1,80 @@
+package mock
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+)
+
+// recordingLogger is a real logr.Logger that records what it logs.
+type recordingLogger struct {
+	logr.Logger
+	lines []string
+}
+
+func (l *recordingLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.lines = append(l.lines, line(append([]interface{}{msg}, keysAndValues...)))
+}
+
+func (l *recordingLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.lines = append(l.lines, line(append([]interface{}{err, msg}, keysAndValues...)))
+}
+
+func (l *recordingLogger) Enabled() bool {
+	return true
+}
+
+func line(values []interface{}) string {
+	return strings.TrimSpace(fmt.Sprintln(values...))
+}
+
+type spyRecorder []SpiedCall
+
+func (r *spyRecorder) RecordSpiedCall(call SpiedCall) {
+	*r = append(*r, call)
+}
+
+func TestDelegateForwardsCallsNoExpectationMatches(t *testing.T) {
+	p := NewProvider(t)
+	m := p.Logger()
+	m.EXPECT().Enabled().Return(false).Times(1)
+	m.EXPECT().Info("stubbed")
+	real := &recordingLogger{}
+	l := Delegate[logr.Logger](m, real)
+	if l.Enabled() {
+		t.Errorf("first Enabled was not stubbed")
+	}
+	if !l.Enabled() {
+		t.Errorf("second Enabled was not forwarded")
+	}
+	l.Info("stubbed")
+	l.Info("forwarded", "k", 1)
+	if !reflect.DeepEqual(real.lines, []string{"forwarded k 1"}) {
+		t.Errorf("forwarded %q", real.lines)
+	}
+}
+
+func TestSpyRecordsDelegatedVariadicCalls(t *testing.T) {
+	p := NewProvider(t)
+	rec := &spyRecorder{}
+	real := &recordingLogger{}
+	l := Spy[logr.Logger](p.Logger(), real, rec)
+	l.Info("saved", "id", 7)
+	l.Error(errors.New("boom"), "failed")
+	if !reflect.DeepEqual(real.lines, []string{"saved id 7", "boom failed"}) {
+		t.Errorf("forwarded %q", real.lines)
+	}
+	if len(*rec) != 2 {
+		t.Fatalf("recorded %v", *rec)
+	}
+	if call := (*rec)[0]; call.Method != "Info" || !reflect.DeepEqual(call.Args, []interface{}{"saved", "id", 7}) {
+		t.Errorf("recorded %s", call)
+	}
+	if call := (*rec)[1]; call.Method != "Error" || len(call.Args) != 2 {
+		t.Errorf("recorded %s", call)
+	}
+}
@@ -0,0 +1,109 @@
+// Package sqlitedb opens in-memory SQLite databases loaded with fixtures,
+// for repository tests that need a real SQL engine rather than a mock:
+//
+//	db := sqlitedb.Open(x, "testdata/schema.sql", "testdata/users.csv")
+//
+// SQLite is linked in with cgo, so it is a module of its own and only
+// tests that use it depend on it and compile it.  Built without cgo, Open
+// fails the test.
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/sbernheim/goonit/core"
+)
+
+// Counts the SQLite databases opened so each gets its own in-memory name.
+var opened int64
+
+// Opens an in-memory SQLite database, applies the schema file, such as
+// "testdata/schema.sql", then loads each fixture file in order, and closes
+// the database when the test is Done.  A .sql fixture is executed as is.
+// A .csv fixture is inserted into the table named after the file, so
+// "testdata/users.csv" fills users, with the header row naming the columns;
+// empty fields are inserted as NULL.
+func Open(x *core.BaseTest, schema string, fixtures ...string) *sql.DB {
+	name := fmt.Sprintf("file:goonit%d?mode=memory&cache=shared", atomic.AddInt64(&opened, 1))
+	db, err := sql.Open("sqlite3", name)
+	if err != nil {
+		x.Fatalf("failed to open SQLite database '%s': %s", name, err.Error())
+	}
+	// The in-memory database lasts as long as a connection to it is open,
+	// so hold one until the test is Done.
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		db.Close()
+		x.Fatalf("failed to connect to SQLite database '%s': %s", name, err.Error())
+	}
+	x.DoAfter(func() {
+		conn.Close()
+		db.Close()
+	})
+	for _, path := range append([]string{schema}, fixtures...) {
+		loadFixture(x, db, path)
+	}
+	return db
+}
+
+func loadFixture(x *core.BaseTest, db *sql.DB, path string) {
+	if strings.ToLower(filepath.Ext(path)) == ".csv" {
+		loadCSVFixture(x, db, path)
+		return
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		x.Fatalf("failed to read SQL file '%s': %s", path, err.Error())
+	}
+	if _, err := db.Exec(string(data)); err != nil {
+		x.Fatalf("failed to execute SQL file '%s': %s", path, err.Error())
+	}
+}
+
+func loadCSVFixture(x *core.BaseTest, db *sql.DB, path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		x.Fatalf("failed to open CSV fixture '%s': %s", path, err.Error())
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		x.Fatalf("failed to read CSV fixture '%s': %s", path, err.Error())
+	}
+	if len(records) == 0 {
+		x.Fatalf("CSV fixture '%s' has no header row naming the columns", path)
+	}
+	table := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	columns := make([]string, len(records[0]))
+	params := make([]string, len(records[0]))
+	for i, column := range records[0] {
+		columns[i] = quoteIdent(column)
+		params[i] = "?"
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quoteIdent(table), strings.Join(columns, ", "), strings.Join(params, ", "))
+	for i, record := range records[1:] {
+		values := make([]interface{}, len(record))
+		for j, field := range record {
+			if field != "" {
+				values[j] = field
+			}
+		}
+		if _, err := db.Exec(insert, values...); err != nil {
+			x.Fatalf("failed to insert row %d of CSV fixture '%s': %s", i+1, path, err.Error())
+		}
+	}
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
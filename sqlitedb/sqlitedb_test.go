@@ -0,0 +1,75 @@
+package sqlitedb_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/sbernheim/goonit/core"
+	"github.com/sbernheim/goonit/sqlitedb"
+)
+
+func tableExists(t *testing.T, db *sql.DB, table string) bool {
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n == 1
+}
+
+func TestOpenLoadsFixtures(t *testing.T) {
+	x := core.New(t)
+	defer x.Done()
+	db := sqlitedb.Open(x, "testdata/schema.sql", "testdata/users.csv")
+	var name string
+	var email sql.NullString
+	if err := db.QueryRow("SELECT name, email FROM users WHERE id = 1").Scan(&name, &email); err != nil {
+		t.Fatal(err)
+	}
+	if name != "ann" || email.Valid {
+		t.Fatalf("user 1 is %q with email %v", name, email)
+	}
+	if err := db.QueryRow("SELECT name FROM users WHERE id = 2").Scan(&name); err != nil || name != "bo, b" {
+		t.Fatalf("user 2 is %q, %v", name, err)
+	}
+}
+
+func TestMigrateWithTeardownRunsBeforeDatabaseCloses(t *testing.T) {
+	x := core.New(t)
+	db := sqlitedb.Open(x, "testdata/schema.sql")
+	x.MigrateWithTeardown(db, "testdata/migrations")
+	if !tableExists(t, db, "posts") || !tableExists(t, db, "tags") {
+		t.Fatal("migrations were not applied")
+	}
+	// The teardown reports through t if the database is already closed.
+	x.Done()
+	if t.Failed() {
+		t.Fatal("migrations were not torn down while the database was open")
+	}
+}
+
+func TestMigrateOnceAndTeardownByOwner(t *testing.T) {
+	x := core.New(t)
+	defer x.Done()
+	db := sqlitedb.Open(x, "testdata/schema.sql")
+	x.Migrate(db, "testdata/migrations")
+	if _, err := db.Exec("DROP TABLE tags"); err != nil {
+		t.Fatal(err)
+	}
+	x.Migrate(db, "testdata/migrations")
+	if tableExists(t, db, "tags") {
+		t.Fatal("Migrate ran again for a database it already migrated")
+	}
+	if _, err := db.Exec("CREATE TABLE tags (name TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := core.TeardownMigrations(db, "testdata/migrations"); err != nil {
+		t.Fatal(err)
+	}
+	if tableExists(t, db, "posts") || tableExists(t, db, "tags") {
+		t.Fatal("TeardownMigrations left migrated tables")
+	}
+	x.Migrate(db, "testdata/migrations")
+	if !tableExists(t, db, "posts") {
+		t.Fatal("Migrate did not apply the migrations again after TeardownMigrations")
+	}
+}
@@ -0,0 +1,51 @@
+// Package testify adapts goonit's BaseTest to testify suites so existing
+// suites can adopt goonit's mocks, captures, env and temp helpers one test at
+// a time without rewriting their assertions.
+//
+// Embed Suite in place of suite.Suite:
+//
+//	type ServiceSuite struct {
+//		testify.Suite
+//	}
+//
+//	func (s *ServiceSuite) TestSomething() {
+//		s.SetEnv("NAME", "value")
+//		s.Require().NoError(doSomething(s.TempDir()))
+//	}
+//
+// Suites that define their own SetupTest or TearDownTest must call the
+// embedded Suite's method so each test gets a fresh BaseTest.
+package testify
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/sbernheim/goonit/core"
+)
+
+type Suite struct {
+	suite.Suite
+	*core.BaseTest
+	Options []core.Option
+}
+
+// Creates a fresh BaseTest for the test about to run.
+func (s *Suite) SetupTest() {
+	s.BaseTest = core.New(s.T(), s.Options...)
+}
+
+// Runs the BaseTest's cleanups, including verifying its mocks.
+func (s *Suite) TearDownTest() {
+	if s.BaseTest != nil {
+		s.BaseTest.Done()
+		s.BaseTest = nil
+	}
+}
+
+// Returns testify's require assertions for the test, as suite.Suite does.
+// BaseTest's own Require, a Gomega assertion, would otherwise make the
+// selector ambiguous; call it as s.BaseTest.Require.
+func (s *Suite) Require() *require.Assertions {
+	return s.Suite.Require()
+}
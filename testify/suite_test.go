@@ -0,0 +1,27 @@
+package testify_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/sbernheim/goonit/testify"
+)
+
+type ExampleSuite struct {
+	testify.Suite
+}
+
+func (s *ExampleSuite) TestRequireAndBaseTest() {
+	s.SetEnv("GOONIT_EXAMPLE", "value")
+	s.Require().Equal("value", s.Getenv("GOONIT_EXAMPLE"))
+	s.Require().NoError(os.WriteFile(filepath.Join(s.TempDir(), "file"), []byte("data"), 0600))
+	s.BaseTest.Require(s.Getenv("GOONIT_EXAMPLE")).To(Equal("value"))
+}
+
+func TestExampleSuite(t *testing.T) {
+	suite.Run(t, new(ExampleSuite))
+}
@@ -0,0 +1,252 @@
+// Package wsx runs scripted WebSocket servers for tests of WebSocket
+// clients:
+//
+//	s := wsx.Start(x).Send("hello").Echo()
+//	client := Dial(s.WSURL())
+//
+// It is kept out of package core and httpx so only tests that use it
+// depend on the WebSocket library.
+package wsx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sbernheim/goonit/core"
+)
+
+// T is the part of *testing.T the server reports failures through.
+type T interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// Message is a message a Server received.
+type Message struct {
+	Type int
+	Data []byte
+}
+
+func (m Message) String() string {
+	return string(m.Data)
+}
+
+type wsStep struct {
+	message Message
+	delay   time.Duration
+	close   bool
+	code    int
+}
+
+// Server is a WebSocket server that sends each connection a script of
+// frames, optionally echoes what it receives, and records every message.
+type Server struct {
+	*httptest.Server
+	t        T
+	upgrader websocket.Upgrader
+	mu       sync.Mutex
+	script   []wsStep
+	echo     bool
+	conns    []*websocket.Conn
+	received []Message
+	changed  chan struct{}
+	closed   bool
+	closing  chan struct{}
+}
+
+// Starts a WebSocket server reporting failures through t.  Close it when
+// the test is done.
+func NewServer(t T) *Server {
+	s := &Server{
+		t:        t,
+		upgrader: websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }},
+		changed:  make(chan struct{}),
+		closing:  make(chan struct{}),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Starts a WebSocket server that sends each connection scripted frames
+// and records what it receives, shut down when the test is Done.
+func Start(x *core.BaseTest) *Server {
+	s := NewServer(x.TestingT())
+	x.DebugInfo("WebSocket server", s.WSURL())
+	x.DoAfter(s.Close)
+	return s
+}
+
+// Returns the ws:// URL of the server.
+func (s *Server) WSURL() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+// Adds a text frame to the script each connection is sent.
+func (s *Server) Send(text string) *Server {
+	return s.step(wsStep{message: Message{Type: websocket.TextMessage, Data: []byte(text)}})
+}
+
+// Adds a text frame holding the value encoded as JSON to the script.
+func (s *Server) SendJSON(v interface{}) *Server {
+	data, err := json.Marshal(v)
+	if err != nil {
+		s.t.Fatalf("failed to encode WebSocket frame as JSON: %s", err.Error())
+	}
+	return s.step(wsStep{message: Message{Type: websocket.TextMessage, Data: data}})
+}
+
+func (s *Server) SendBinary(data []byte) *Server {
+	return s.step(wsStep{message: Message{Type: websocket.BinaryMessage, Data: data}})
+}
+
+// Adds a pause before the next scripted frame.
+func (s *Server) Wait(d time.Duration) *Server {
+	return s.step(wsStep{delay: d})
+}
+
+// Ends the script by closing the connection with the close code, such as
+// websocket.CloseGoingAway, for testing client reconnects.
+func (s *Server) CloseWith(code int) *Server {
+	return s.step(wsStep{close: true, code: code})
+}
+
+// Sends every message received back to the connection it came from.
+func (s *Server) Echo() *Server {
+	s.mu.Lock()
+	s.echo = true
+	s.mu.Unlock()
+	return s
+}
+
+func (s *Server) step(step wsStep) *Server {
+	s.mu.Lock()
+	s.script = append(s.script, step)
+	s.mu.Unlock()
+	return s
+}
+
+// Returns how many connections the server accepted.
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.conns)
+}
+
+// Returns every message received, from all connections, in order.
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message{}, s.received...)
+}
+
+// Fails the test unless a message with the data is received within the
+// duration, counting messages received before the call.
+func (s *Server) ExpectMessage(expected string, within time.Duration) *Server {
+	s.t.Helper()
+	timeout := time.After(within)
+	for {
+		s.mu.Lock()
+		changed := s.changed
+		s.mu.Unlock()
+		for _, m := range s.Messages() {
+			if string(m.Data) == expected {
+				return s
+			}
+		}
+		select {
+		case <-changed:
+		case <-timeout:
+			s.t.Fatalf("expected WebSocket message %q within %s but received %v", expected, within, s.Messages())
+			return s
+		}
+	}
+}
+
+// Closes every connection, cutting short any Wait in their scripts, and
+// then the server.
+func (s *Server) Close() {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.closing)
+	}
+	conns := append([]*websocket.Conn{}, s.conns...)
+	s.mu.Unlock()
+	for _, conn := range conns {
+		conn.Close()
+	}
+	s.Server.Close()
+}
+
+func (s *Server) serve(w http.ResponseWriter, req *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		s.t.Errorf("WebSocket server failed to upgrade %s %s: %s", req.Method, req.URL, err.Error())
+		return
+	}
+	defer conn.Close()
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.conns = append(s.conns, conn)
+	script := append([]wsStep{}, s.script...)
+	s.mu.Unlock()
+	// Gorilla connections allow one writer at a time.
+	writeMu := &sync.Mutex{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.read(conn, writeMu)
+	}()
+	for _, step := range script {
+		switch {
+		case step.delay > 0:
+			select {
+			case <-time.After(step.delay):
+			case <-s.closing:
+				return
+			}
+		case step.close:
+			msg := websocket.FormatCloseMessage(step.code, "")
+			conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+			return
+		default:
+			writeMu.Lock()
+			err := conn.WriteMessage(step.message.Type, step.message.Data)
+			writeMu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}
+	<-done
+}
+
+func (s *Server) read(conn *websocket.Conn, writeMu *sync.Mutex) {
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.received = append(s.received, Message{Type: messageType, Data: data})
+		echo := s.echo
+		close(s.changed)
+		s.changed = make(chan struct{})
+		s.mu.Unlock()
+		if echo {
+			writeMu.Lock()
+			conn.WriteMessage(messageType, data)
+			writeMu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package wsx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sbernheim/goonit/core"
+)
+
+func TestCloseEndsOpenConnections(t *testing.T) {
+	s := NewServer(t).Send("hello").Wait(time.Minute).Send("too late")
+	conn, _, err := websocket.DefaultDialer.Dial(s.WSURL(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, data, err := conn.ReadMessage(); err != nil || string(data) != "hello" {
+		t.Fatalf("read %q, %v", data, err)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		s.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked on the open connection")
+	}
+	if _, data, err := conn.ReadMessage(); err == nil {
+		t.Fatalf("read %q after Close", data)
+	}
+}
+
+func TestStartedServerLeavesNoGoroutinesRunning(t *testing.T) {
+	x := core.New(t, core.WithoutMocks())
+	x.CheckGoroutines()
+	s := Start(x).Send("hello").Wait(time.Minute)
+	conn, _, err := websocket.DefaultDialer.Dial(s.WSURL(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, data, err := conn.ReadMessage(); err != nil || string(data) != "hello" {
+		t.Fatalf("read %q, %v", data, err)
+	}
+	x.Done()
+}